@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/middleware"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ApiClientHandler struct {
+	app     *api.ApiApp
+	clientS service.ApiClientService
+}
+
+func NewApiClientHandler(app *api.ApiApp, client service.ApiClientService) *ApiClientHandler {
+	return &ApiClientHandler{app, client}
+}
+
+func (h *ApiClientHandler) Create(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.ApiClientCreateRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Create client
+	client, secret, err := h.clientS.CreateClient(dbctx, req, userData.Code, middleware.Permissions())
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.ApiClientResponse
+	response.Transform(client, secret)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *ApiClientHandler) GetList(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Get data
+	list, total, pg, err := h.clientS.FindAllClient(dbctx, c)
+	if err != nil {
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var listResp []responses.ApiClientResponse
+	for _, data := range list {
+		var resp responses.ApiClientResponse
+		resp.Transform(data, "")
+		listResp = append(listResp, resp)
+	}
+
+	pathResp := fmt.Sprintf(`%s?`, c.Route().Path)
+	addResp := utils.WithPagination(listResp, total, common.OrderByOffsetLimitPaginateLink(pg, pathResp, nil))
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", addResp)
+}
+
+func (h *ApiClientHandler) Get(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Find data
+	client, err := h.clientS.FindClient(dbctx, c.Params("code"))
+	if err != nil {
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.ApiClientResponse
+	response.Transform(client, "")
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *ApiClientHandler) Update(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.ApiClientUpdateRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Update client
+	client, err := h.clientS.UpdateClient(dbctx, req, userData.Code, c.Params("code"), middleware.Permissions())
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.ApiClientResponse
+	response.Transform(client, "")
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *ApiClientHandler) Delete(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Delete client
+	err = h.clientS.DeleteClient(dbctx, userData.Code, c.Params("code"))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *ApiClientHandler) RotateSecret(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.ApiClientSecretRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Rotate secret
+	client, secret, err := h.clientS.RotateSecret(dbctx, req, userData.Code, c.Params("code"))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.ApiClientResponse
+	response.Transform(client, secret)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
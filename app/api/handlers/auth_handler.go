@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fiber-starter/app/api"
+	"fiber-starter/app/api/middleware"
 	"fiber-starter/app/api/requests"
 	"fiber-starter/app/api/responses"
 	"fiber-starter/app/service"
@@ -13,12 +15,13 @@ import (
 )
 
 type AuthHandler struct {
-	app   *api.ApiApp
-	authS service.AuthService
+	app      *api.ApiApp
+	authS    service.AuthService
+	lockoutS service.LockoutService
 }
 
-func NewAuthHandler(app *api.ApiApp, auth service.AuthService) *AuthHandler {
-	return &AuthHandler{app, auth}
+func NewAuthHandler(app *api.ApiApp, auth service.AuthService, lockout service.LockoutService) *AuthHandler {
+	return &AuthHandler{app, auth, lockout}
 }
 
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
@@ -51,9 +54,13 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	dbctx.Set(ctx, conn, tx)
 
 	// Registration
-	user, otpToken, err := h.authS.Registration(dbctx, req, c.Get("X-Channel"), h.app.RabbitMQ)
+	user, err := h.authS.Registration(dbctx, req, middleware.Client(c))
 	if err != nil {
 		tx.Rollback(ctx)
+		var policyErr utils.PasswordPolicyErrors
+		if errors.As(err, &policyErr) {
+			return utils.APIResponseErrorByPasswordPolicy(c, policyErr, h.app.Validator.Translator)
+		}
 		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
@@ -66,7 +73,7 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 	// Set response
 	var response responses.RegisterResponse
-	response.Transform(user, otpToken)
+	response.Transform(user)
 
 	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
 }
@@ -90,6 +97,11 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	}
 	defer conn.Release()
 
+	// Check account and ip lockout
+	if err := h.lockoutS.Check(ctx, req.Email, c.IP()); err != nil {
+		return lockoutResponse(c, err)
+	}
+
 	// Set Tx transaction
 	tx, err := h.app.DB.Begin(ctx)
 	if err != nil {
@@ -101,9 +113,14 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	dbctx.Set(ctx, conn, tx)
 
 	// Login
-	user, otpToken, err := h.authS.AuthLogin(dbctx, req, c.Get("X-Channel"), h.app.RabbitMQ)
+	user, token, err := h.authS.AuthLogin(dbctx, req, middleware.Client(c), middleware.Device(c))
 	if err != nil {
 		tx.Rollback(ctx)
+		if errors.Is(err, service.ErrInvalidCredential) {
+			if err := h.lockoutS.Fail(dbctx, req.Email, c.IP()); err != nil {
+				return lockoutResponse(c, err)
+			}
+		}
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
@@ -114,9 +131,69 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
+	// Reset failed attempts of account
+	if err := h.lockoutS.Reset(ctx, req.Email); err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set response
+	var response responses.LoginResponse
+	response.Transform(user, token)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *AuthHandler) RefreshToken(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.RefreshTokenRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Rotate refresh token
+	user, token, err := h.authS.RefreshToken(dbctx, req)
+	if err != nil {
+		// Keep the family revocation when reuse detected
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			tx.Commit(ctx)
+		} else {
+			tx.Rollback(ctx)
+		}
+		return utils.APIResponse(c, err.Error(), fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
 	// Set response
 	var response responses.LoginResponse
-	response.Transform(user, otpToken)
+	response.Transform(user, token)
 
 	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
 }
@@ -151,7 +228,7 @@ func (h *AuthHandler) SendOTPToken(c *fiber.Ctx) error {
 	dbctx.Set(ctx, conn, tx)
 
 	// Forgot assword
-	otpToken, status, err := h.authS.SendOTPTokenByType(dbctx, req.EmailPhone, c.Get("X-Channel"), c.Params("type"), h.app.RabbitMQ)
+	status, err := h.authS.SendOTPTokenByType(dbctx, req.EmailPhone, middleware.Client(c), c.Params("type"))
 	if err != nil {
 		tx.Rollback(ctx)
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
@@ -166,7 +243,7 @@ func (h *AuthHandler) SendOTPToken(c *fiber.Ctx) error {
 
 	// Set response
 	var response responses.SendOTPTokenResponse
-	response.Transform(otpToken, status, req.EmailPhone)
+	response.Transform(status, req.EmailPhone)
 
 	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
 }
@@ -201,9 +278,128 @@ func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
 	dbctx.Set(ctx, conn, tx)
 
 	// Change password
-	err = h.authS.ChangePassword(dbctx, req, c.Get("X-Channel"))
+	err = h.authS.ChangePassword(dbctx, req, middleware.Client(c))
 	if err != nil {
 		tx.Rollback(ctx)
+		var policyErr utils.PasswordPolicyErrors
+		if errors.As(err, &policyErr) {
+			return utils.APIResponseErrorByPasswordPolicy(c, policyErr, h.app.Validator.Translator)
+		}
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *AuthHandler) PasswordChange(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.PasswordChangeRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Password change token, revoked once the new password is committed
+	tokenMetaData, err := utils.DecodeScopedJWT(req.PasswordChangeToken, utils.JWT_TYPE_PASSWORD)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Set required password and login
+	user, token, err := h.authS.PasswordChange(dbctx, req, middleware.Device(c))
+	if err != nil {
+		tx.Rollback(ctx)
+		var policyErr utils.PasswordPolicyErrors
+		if errors.As(err, &policyErr) {
+			return utils.APIResponseErrorByPasswordPolicy(c, policyErr, h.app.Validator.Translator)
+		}
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Password change token can only be used once
+	if err := utils.RevokeToken(tokenMetaData); err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set response
+	var response responses.LoginResponse
+	response.Transform(user, token)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *AuthHandler) AcceptInvitation(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.AcceptInvitationRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Set password of invitation
+	err = h.authS.AcceptInvitation(dbctx, req)
+	if err != nil {
+		tx.Rollback(ctx)
+		var policyErr utils.PasswordPolicyErrors
+		if errors.As(err, &policyErr) {
+			return utils.APIResponseErrorByPasswordPolicy(c, policyErr, h.app.Validator.Translator)
+		}
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
@@ -236,6 +432,11 @@ func (h *AuthHandler) ValidateOTPToken(c *fiber.Ctx) error {
 	}
 	defer conn.Release()
 
+	// Check account and ip lockout
+	if err := h.lockoutS.Check(ctx, req.EmailPhone, c.IP()); err != nil {
+		return lockoutResponse(c, err)
+	}
+
 	// Set Tx transaction
 	tx, err := h.app.DB.Begin(ctx)
 	if err != nil {
@@ -247,9 +448,81 @@ func (h *AuthHandler) ValidateOTPToken(c *fiber.Ctx) error {
 	dbctx.Set(ctx, conn, tx)
 
 	// Validate
-	err = h.authS.OTPTokenValidation(dbctx, req, c.Get("X-Channel"), c.Params("type"))
+	err = h.authS.OTPTokenValidation(dbctx, req, middleware.Client(c), c.Params("type"))
+	if err != nil {
+		// Keep the used otp attempt when the otp is wrong
+		if errors.Is(err, service.ErrInvalidOTP) {
+			tx.Commit(ctx)
+			if err := h.lockoutS.Fail(dbctx, req.EmailPhone, c.IP()); err != nil {
+				return lockoutResponse(c, err)
+			}
+		} else {
+			tx.Rollback(ctx)
+		}
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
 	if err != nil {
 		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Reset failed attempts of account
+	if err := h.lockoutS.Reset(ctx, req.EmailPhone); err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *AuthHandler) PasswordlessLogin(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.ValidateOTPTokenRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Check account and ip lockout
+	if err := h.lockoutS.Check(ctx, req.EmailPhone, c.IP()); err != nil {
+		return lockoutResponse(c, err)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Login with otp or link token
+	user, token, err := h.authS.PasswordlessLogin(dbctx, req, middleware.Client(c), middleware.Device(c))
+	if err != nil {
+		// Keep the used otp attempt when the otp is wrong
+		if errors.Is(err, service.ErrInvalidOTP) {
+			tx.Commit(ctx)
+			if err := h.lockoutS.Fail(dbctx, req.EmailPhone, c.IP()); err != nil {
+				return lockoutResponse(c, err)
+			}
+		} else {
+			tx.Rollback(ctx)
+		}
 		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
@@ -260,5 +533,201 @@ func (h *AuthHandler) ValidateOTPToken(c *fiber.Ctx) error {
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
+	// Reset failed attempts of account
+	if err := h.lockoutS.Reset(ctx, req.EmailPhone); err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set response
+	var response responses.LoginResponse
+	response.Transform(user, token)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	// Define request, refresh token is optional
+	var req requests.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get token metadata
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Logout current session
+	err = h.authS.Logout(dbctx, req, userData)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get token metadata
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Logout every session
+	err = h.authS.LogoutAll(dbctx, userData.ID, userData.Code)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
 	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
 }
+
+func (h *AuthHandler) JWKS(c *fiber.Ctx) error {
+	// JWKS document is served as is for token verifiers
+	return c.JSON(utils.GetJWKS())
+}
+
+func (h *AuthHandler) MFAVerify(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.MFAVerifyRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Account of the pending mfa token, failures are counted against it like a login
+	tokenMetaData, err := utils.DecodeScopedJWT(req.MFAToken, utils.JWT_TYPE_MFA)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+	}
+	account := tokenMetaData.Email
+	if len(account) == 0 {
+		account = tokenMetaData.Code
+	}
+
+	// Check account and ip lockout
+	if err := h.lockoutS.Check(ctx, account, c.IP()); err != nil {
+		return lockoutResponse(c, err)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Verify second factor
+	user, token, err := h.authS.MFAVerify(dbctx, req, middleware.Device(c))
+	if err != nil {
+		tx.Rollback(ctx)
+		if errors.Is(err, service.ErrInvalidMFACode) {
+			if err := h.lockoutS.Fail(dbctx, account, c.IP()); err != nil {
+				return lockoutResponse(c, err)
+			}
+		}
+		return utils.APIResponse(c, err.Error(), fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Pending mfa token can only be used once
+	if err := utils.RevokeToken(tokenMetaData); err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Reset failed attempts of account
+	if err := h.lockoutS.Reset(ctx, account); err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set response
+	var response responses.LoginResponse
+	response.Transform(user, token)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+// lockoutResponse answer 429 while locked, other errors are internal
+func lockoutResponse(c *fiber.Ctx, err error) error {
+	var lockoutErr *service.LockoutError
+	if errors.As(err, &lockoutErr) {
+		return utils.APIResponseTooManyRequests(c, lockoutErr.Error(), lockoutErr.RetryAfter)
+	}
+
+	return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+}
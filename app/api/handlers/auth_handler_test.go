@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/model"
+	"fiber-starter/app/service"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgproto3/v2"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// startTestPostgres answer the begin, commit and rollback of the handlers, enough of postgres for the transaction
+func startTestPostgres(t *testing.T) *pgxpool.Pool {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestPostgres(conn)
+		}
+	}()
+
+	pool, err := pgxpool.Connect(context.Background(), "postgres://test@"+listener.Addr().String()+"/test?sslmode=disable")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+
+	return pool
+}
+
+func serveTestPostgres(conn net.Conn) {
+	defer conn.Close()
+	backend := pgproto3.NewBackend(pgproto3.NewChunkReader(conn), conn)
+
+	if _, err := backend.ReceiveStartupMessage(); err != nil {
+		return
+	}
+	for _, msg := range []pgproto3.BackendMessage{
+		&pgproto3.AuthenticationOk{},
+		&pgproto3.ParameterStatus{Name: "server_version", Value: "13.0"},
+		&pgproto3.BackendKeyData{ProcessID: 1, SecretKey: 1},
+		&pgproto3.ReadyForQuery{TxStatus: 'I'},
+	} {
+		if err := backend.Send(msg); err != nil {
+			return
+		}
+	}
+
+	txStatus := byte('I')
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+
+		switch msg := msg.(type) {
+		case *pgproto3.Query:
+			command := strings.ToUpper(strings.TrimSpace(msg.String))
+			if strings.HasPrefix(command, "BEGIN") {
+				txStatus = 'T'
+			} else {
+				txStatus = 'I'
+			}
+			backend.Send(&pgproto3.CommandComplete{CommandTag: []byte(strings.Fields(command)[0])})
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: txStatus})
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
+
+// testAuthService the service of a registered and logged in user
+type testAuthService struct {
+	service.AuthService
+}
+
+func (testAuthService) Registration(dbctx db.DBCtx, req requests.RegisterRequest, client model.ApiClient) (model.User, error) {
+	return model.User{ID: 1, Code: "USR-1", Name: req.Name, Email: req.Email, Phone: req.Phone}, nil
+}
+
+func (testAuthService) AuthLogin(dbctx db.DBCtx, req requests.LoginRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error) {
+	return model.User{ID: 1, Code: "USR-1", Email: req.Email, Status: true}, model.AuthToken{AccessToken: "access", RefreshToken: "refresh"}, nil
+}
+
+// testLockoutService never locks out
+type testLockoutService struct {
+	service.LockoutService
+}
+
+func (testLockoutService) Check(ctx context.Context, account string, ip string) error {
+	return nil
+}
+
+func (testLockoutService) Reset(ctx context.Context, account string) error {
+	return nil
+}
+
+func newAuthTestApp(t *testing.T) *fiber.App {
+	handler := NewAuthHandler(&api.ApiApp{
+		Config:    &config.Config{},
+		DB:        startTestPostgres(t),
+		Validator: config.SetupValidator(&config.AppConfig{}),
+	}, testAuthService{}, testLockoutService{})
+
+	app := fiber.New()
+	app.Post("/auth/register", handler.Register)
+	app.Post("/auth/login", handler.Login)
+
+	return app
+}
+
+func TestAuthResponsesWithoutOTP(t *testing.T) {
+	app := newAuthTestApp(t)
+
+	tests := []struct {
+		path string
+		body string
+	}{
+		{"/auth/register", `{"name":"User","email":"user@sample.com","password":"Secret-123","confirm_password":"Secret-123","phone":"+6281234567890"}`},
+		{"/auth/login", `{"email":"user@sample.com","password":"Secret-123"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			request := httptest.NewRequest(fiber.MethodPost, tt.path, strings.NewReader(tt.body))
+			request.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+
+			response, err := app.Test(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response.StatusCode != fiber.StatusOK {
+				t.Fatalf("status %d: %s", response.StatusCode, raw)
+			}
+
+			// The otp is only delivered to the email
+			var body struct {
+				Result map[string]json.RawMessage `json:"result"`
+			}
+			if err := json.Unmarshal(raw, &body); err != nil {
+				t.Fatal(err)
+			}
+			if len(body.Result) <= 0 {
+				t.Fatalf("empty result: %s", raw)
+			}
+			if _, ok := body.Result["otp"]; ok {
+				t.Errorf("result has the otp: %s", raw)
+			}
+		})
+	}
+}
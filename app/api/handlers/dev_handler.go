@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type DevHandler struct {
+	app *api.ApiApp
+}
+
+func NewDevHandler(app *api.ApiApp) *DevHandler {
+	return &DevHandler{app}
+}
+
+// Mailbox list the mails written by the file transport
+func (h *DevHandler) Mailbox(c *fiber.Ctx) error {
+	limit, err := common.GetIntParam(c, "limit")
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+
+	list, err := utils.MailboxMessages(h.app.Config.Mail.FileDir, limit)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set response
+	listResp := []responses.MailboxMessageResponse{}
+	for _, data := range list {
+		var resp responses.MailboxMessageResponse
+		resp.Transform(data)
+		listResp = append(listResp, resp)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", listResp)
+}
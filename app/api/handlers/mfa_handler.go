@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type MFAHandler struct {
+	app  *api.ApiApp
+	mfaS service.MFAService
+}
+
+func NewMFAHandler(app *api.ApiApp, mfa service.MFAService) *MFAHandler {
+	return &MFAHandler{app, mfa}
+}
+
+func (h *MFAHandler) Enroll(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Enroll authenticator
+	secret, provisioningURI, err := h.mfaS.Enroll(dbctx, userData.ID, userData.Email)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.MFAEnrollResponse
+	response.Transform(secret, provisioningURI)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *MFAHandler) Enable(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.MFACodeRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Enable mfa with the first authenticator code
+	recoveryCodes, err := h.mfaS.Enable(dbctx, userData.ID, req.Code)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.MFARecoveryCodesResponse
+	response.Transform(recoveryCodes)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *MFAHandler) Disable(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.MFACodeRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Disable mfa
+	err = h.mfaS.Disable(dbctx, userData.ID, req.Code)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/app/model"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationHandler struct {
+	app           *api.ApiApp
+	notificationS service.NotificationService
+}
+
+func NewNotificationHandler(app *api.ApiApp, notification service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{app, notification}
+}
+
+func (h *NotificationHandler) GetList(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Get inbox
+	list, total, pg, err := h.notificationS.FindInbox(dbctx, userData.ID, c)
+	if err != nil {
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	listResp := []responses.NotificationResponse{}
+	for _, data := range list {
+		var resp responses.NotificationResponse
+		resp.Transform(data)
+		listResp = append(listResp, resp)
+	}
+
+	var additional map[string]string
+	if len(c.Query("unread")) > 0 {
+		additional = map[string]string{"unread": c.Query("unread")}
+	}
+	pathResp := fmt.Sprintf(`%s?`, c.Route().Path)
+	addResp := utils.WithPagination(listResp, total, common.OrderByOffsetLimitPaginateLink(pg, pathResp, additional))
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", addResp)
+}
+
+func (h *NotificationHandler) MarkRead(c *fiber.Ctx) error {
+	// Check notification id
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.APIResponse(c, service.ErrNotificationNotFound.Error(), fiber.StatusNotFound, fiber.ErrNotFound.Error(), nil)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Mark notification of user as read
+	err = h.notificationS.MarkRead(dbctx, userData.ID, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			return utils.APIResponse(c, err.Error(), fiber.StatusNotFound, fiber.ErrNotFound.Error(), nil)
+		}
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *NotificationHandler) MarkAllRead(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Mark every notification of user as read
+	err = h.notificationS.MarkAllRead(dbctx, userData.ID)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *NotificationHandler) GetPreferences(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Get preferences
+	list, err := h.notificationS.FindPreferences(dbctx, userData.ID)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", preferencesResponse(list))
+}
+
+func (h *NotificationHandler) UpdatePreferences(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.NotificationPreferenceRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Save preferences
+	list, err := h.notificationS.UpdatePreferences(dbctx, userData.ID, req)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", preferencesResponse(list))
+}
+
+func preferencesResponse(list []model.NotificationPreference) []responses.NotificationPreferenceResponse {
+	listResp := []responses.NotificationPreferenceResponse{}
+	for _, data := range list {
+		var resp responses.NotificationPreferenceResponse
+		resp.Transform(data)
+		listResp = append(listResp, resp)
+	}
+
+	return listResp
+}
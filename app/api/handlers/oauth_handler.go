@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/app/model"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OAuthHandler struct {
+	app    *api.ApiApp
+	oauthS service.OAuthService
+}
+
+func NewOAuthHandler(app *api.ApiApp, oauth service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{app, oauth}
+}
+
+func (h *OAuthHandler) Consent(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.OAuthAuthorizeRequest
+	err := c.QueryParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Find client and scopes to consent
+	client, scopes, err := h.oauthS.Consent(dbctx, req, userData)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.OAuthConsentResponse
+	response.Transform(client, req.RedirectURI, scopes)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *OAuthHandler) Approve(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.OAuthAuthorizeRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Grant authorization code
+	redirectURI, err := h.oauthS.Approve(dbctx, req, userData)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.OAuthRedirectResponse
+	response.Transform(redirectURI)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *OAuthHandler) Token(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.OAuthTokenRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return oauthErrorResponse(c, model.NewOAuthError("invalid_request", err.Error()))
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return oauthErrorResponse(c, model.NewOAuthError("invalid_request", err.Error()))
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Issue token of the grant
+	token, scopes, err := h.oauthS.Token(dbctx, req)
+	if err != nil {
+		// Keep the family revocation when reuse detected
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			tx.Commit(ctx)
+		} else {
+			tx.Rollback(ctx)
+		}
+		return oauthErrorResponse(c, err)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return oauthErrorResponse(c, err)
+	}
+
+	// Set response
+	var response responses.OAuthTokenResponse
+	response.Transform(token, scopes, time.Now().Unix())
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(response)
+}
+
+func (h *OAuthHandler) Introspect(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.OAuthTokenActionRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return oauthErrorResponse(c, model.NewOAuthError("invalid_request", err.Error()))
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return oauthErrorResponse(c, model.NewOAuthError("invalid_request", err.Error()))
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+	defer conn.Release()
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Find token state
+	tokenMetaData, tokenType, err := h.oauthS.Introspect(dbctx, req)
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	// Set response
+	var response responses.OAuthIntrospectResponse
+	response.Transform(tokenMetaData, tokenType)
+
+	return c.JSON(response)
+}
+
+func (h *OAuthHandler) Revoke(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.OAuthTokenActionRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return oauthErrorResponse(c, model.NewOAuthError("invalid_request", err.Error()))
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return oauthErrorResponse(c, model.NewOAuthError("invalid_request", err.Error()))
+	}
+	req.ClientID, req.ClientSecret = clientCredentials(c, req.ClientID, req.ClientSecret)
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return oauthErrorResponse(c, err)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Revoke token
+	err = h.oauthS.Revoke(dbctx, req)
+	if err != nil {
+		tx.Rollback(ctx)
+		return oauthErrorResponse(c, err)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return oauthErrorResponse(c, err)
+	}
+
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// clientCredentials prefer credentials of the basic authorization header, see RFC 6749 section 2.3.1
+func clientCredentials(c *fiber.Ctx, clientID, clientSecret string) (string, string) {
+	auth := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(auth, "Basic ") {
+		return clientID, clientSecret
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "Basic "))
+	if err != nil {
+		return clientID, clientSecret
+	}
+	credentials := strings.SplitN(string(decoded), ":", 2)
+	if len(credentials) != 2 {
+		return clientID, clientSecret
+	}
+	id, _ := url.QueryUnescape(credentials[0])
+	secret, _ := url.QueryUnescape(credentials[1])
+
+	return id, secret
+}
+
+// oauthErrorResponse answer the error shaped by RFC 6749 section 5.2
+func oauthErrorResponse(c *fiber.Ctx, err error) error {
+	var oauthErr *model.OAuthError
+	if !errors.As(err, &oauthErr) {
+		// Internal details are only logged, never answered to the client
+		log.Printf("[OAuth - %s %s] %v", c.Method(), c.Path(), err)
+		return c.Status(fiber.StatusInternalServerError).JSON(responses.OAuthErrorResponse{Error: "server_error", ErrorDescription: fiber.ErrInternalServerError.Error()})
+	}
+
+	status := fiber.StatusBadRequest
+	if oauthErr.Code == "invalid_client" {
+		status = fiber.StatusUnauthorized
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+
+	return c.Status(status).JSON(responses.OAuthErrorResponse{Error: oauthErr.Code, ErrorDescription: oauthErr.Description})
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/app/model"
+	"io/ioutil"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func testOAuthErrorResponse(t *testing.T, err error) (int, responses.OAuthErrorResponse) {
+	app := fiber.New()
+	app.Post("/oauth/token", func(c *fiber.Ctx) error {
+		return oauthErrorResponse(c, err)
+	})
+
+	response, err := app.Test(httptest.NewRequest(fiber.MethodPost, "/oauth/token", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var body responses.OAuthErrorResponse
+	if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+
+	return response.StatusCode, body
+}
+
+func TestOAuthErrorResponseHidesServerError(t *testing.T) {
+	output := log.Writer()
+	log.SetOutput(ioutil.Discard)
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	status, body := testOAuthErrorResponse(t, errors.New(`duplicate key value violates unique constraint "oauth_codes_pkey"`))
+	if status != fiber.StatusInternalServerError || body.Error != "server_error" {
+		t.Errorf("status %d, body %+v", status, body)
+	}
+	if strings.Contains(body.ErrorDescription, "oauth_codes") {
+		t.Errorf("internal error is answered: %s", body.ErrorDescription)
+	}
+
+	// OAuth errors keep their description
+	status, body = testOAuthErrorResponse(t, model.NewOAuthError("invalid_grant", "code is expired"))
+	if status != fiber.StatusBadRequest || body.Error != "invalid_grant" || body.ErrorDescription != "code is expired" {
+		t.Errorf("status %d, body %+v", status, body)
+	}
+}
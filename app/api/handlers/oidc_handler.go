@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"context"
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/middleware"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type OIDCHandler struct {
+	app   *api.ApiApp
+	oidcS service.OIDCService
+}
+
+func NewOIDCHandler(app *api.ApiApp, oidc service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{app, oidc}
+}
+
+func (h *OIDCHandler) Authorize(c *fiber.Ctx) error {
+	// Start sign in with the provider
+	authorizationURL, state, err := h.oidcS.Begin(context.Background(), c.Params("provider"), middleware.Client(c))
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.OIDCAuthorizeResponse
+	response.Transform(authorizationURL, state)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *OIDCHandler) Callback(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.OIDCCallbackRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Login with the provider identity
+	user, token, signupToken, err := h.oidcS.Callback(dbctx, c.Params("provider"), req, middleware.Client(c), middleware.Device(c))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// New user requires signup data
+	if len(signupToken) > 0 {
+		var response responses.OIDCSignupResponse
+		response.Transform(signupToken)
+
+		return utils.APIResponse(c, "signup required", fiber.StatusAccepted, "success", response)
+	}
+
+	// Set response
+	var response responses.LoginResponse
+	response.Transform(user, token)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *OIDCHandler) Signup(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.OIDCSignupRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Create user of the provider identity
+	user, token, err := h.oidcS.Signup(dbctx, req, middleware.Client(c), middleware.Device(c))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.LoginResponse
+	response.Transform(user, token)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/middleware"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type PermissionHandler struct {
+	app *api.ApiApp
+}
+
+func NewPermissionHandler(app *api.ApiApp) *PermissionHandler {
+	return &PermissionHandler{app}
+}
+
+func (h *PermissionHandler) GetList(c *fiber.Ctx) error {
+	// Set response from permissions required by registered routes
+	listResp := []responses.PermissionResponse{}
+	for _, permission := range middleware.Permissions() {
+		var resp responses.PermissionResponse
+		resp.Transform(permission)
+		listResp = append(listResp, resp)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", listResp)
+}
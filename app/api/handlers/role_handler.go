@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"fiber-starter/app/api"
+	"fiber-starter/app/api/middleware"
 	"fiber-starter/app/api/requests"
 	"fiber-starter/app/api/responses"
 	"fiber-starter/app/service"
@@ -173,6 +174,13 @@ func (h *RoleHandler) Update(c *fiber.Ctx) error {
 	var dbctx db.DBCtx
 	dbctx.Set(ctx, conn, tx)
 
+	// Find current role, its slug keys the cached permissions
+	oldRole, err := h.roleS.FindRole(dbctx, c.Params("code"))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
 	// Update role
 	role, err := h.roleS.UpdateRole(dbctx, req, userData.Code, c.Params("code"))
 	if err != nil {
@@ -187,6 +195,15 @@ func (h *RoleHandler) Update(c *fiber.Ctx) error {
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
+	// Clear cached permissions of role, by the old slug when renamed
+	err = h.roleS.ForgetPermissions(oldRole)
+	if err == nil && oldRole.Slug != role.Slug {
+		err = h.roleS.ForgetPermissions(role)
+	}
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
 	// Set response
 	var response responses.RoleResponse
 	response.Transform(role)
@@ -219,6 +236,13 @@ func (h *RoleHandler) Delete(c *fiber.Ctx) error {
 	var dbctx db.DBCtx
 	dbctx.Set(ctx, conn, tx)
 
+	// Find role, its slug keys the cached permissions
+	role, err := h.roleS.FindRole(dbctx, c.Params("code"))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
 	// Delete role
 	err = h.roleS.DeleteRole(dbctx, userData.Code, c.Params("code"))
 	if err != nil {
@@ -233,5 +257,161 @@ func (h *RoleHandler) Delete(c *fiber.Ctx) error {
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
+	// Clear cached permissions of role
+	err = h.roleS.ForgetPermissions(role)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
 	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
 }
+
+func (h *RoleHandler) GetPermissions(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Find data
+	role, permissions, err := h.roleS.FindPermissions(dbctx, c.Params("code"))
+	if err != nil {
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	var response responses.RolePermissionResponse
+	response.Transform(role, permissions)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *RoleHandler) GrantPermissions(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.RolePermissionRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Grant permissions
+	role, permissions, err := h.roleS.GrantPermissions(dbctx, req, userData.Code, c.Params("code"), middleware.Permissions())
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Clear cached permissions of role
+	err = h.roleS.ForgetPermissions(role)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set response
+	var response responses.RolePermissionResponse
+	response.Transform(role, permissions)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
+
+func (h *RoleHandler) RevokePermissions(c *fiber.Ctx) error {
+	// Define request with validation
+	var req requests.RolePermissionRequest
+	err := c.BodyParser(&req)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	if err := h.app.Validator.Driver.Struct(req); err != nil {
+		return utils.APIResponseErrorByValidationError(c, err)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Revoke permissions
+	role, permissions, err := h.roleS.RevokePermissions(dbctx, req, userData.Code, c.Params("code"))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Clear cached permissions of role
+	err = h.roleS.ForgetPermissions(role)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set response
+	var response responses.RolePermissionResponse
+	response.Transform(role, permissions)
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fiber-starter/app/api"
+	"fiber-starter/app/api/responses"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SessionHandler struct {
+	app      *api.ApiApp
+	sessionS service.SessionService
+}
+
+func NewSessionHandler(app *api.ApiApp, session service.SessionService) *SessionHandler {
+	return &SessionHandler{app, session}
+}
+
+func (h *SessionHandler) GetList(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Get active sessions
+	list, err := h.sessionS.FindActive(dbctx, userData.ID)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Set response
+	listResp := []responses.UserSessionResponse{}
+	for _, data := range list {
+		var resp responses.UserSessionResponse
+		resp.Transform(data, userData.Session)
+		listResp = append(listResp, resp)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", listResp)
+}
+
+func (h *SessionHandler) Revoke(c *fiber.Ctx) error {
+	// Check session id
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return utils.APIResponse(c, service.ErrSessionNotFound.Error(), fiber.StatusNotFound, fiber.ErrNotFound.Error(), nil)
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user data
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Revoke session of user
+	err = h.sessionS.Revoke(dbctx, userData.ID, id)
+	if err != nil {
+		tx.Rollback(ctx)
+		if errors.Is(err, service.ErrSessionNotFound) {
+			return utils.APIResponse(c, err.Error(), fiber.StatusNotFound, fiber.ErrNotFound.Error(), nil)
+		}
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
@@ -3,9 +3,9 @@ package handlers
 import (
 	"context"
 	"fiber-starter/app/api"
+	"fiber-starter/app/api/middleware"
 	"fiber-starter/app/api/requests"
 	"fiber-starter/app/api/responses"
-	"fiber-starter/app/model"
 	"fiber-starter/app/service"
 	"fiber-starter/db"
 	"fiber-starter/pkg/common"
@@ -16,13 +16,14 @@ import (
 )
 
 type UserHandler struct {
-	app   *api.ApiApp
-	userS service.UserService
-	authS service.AuthService
+	app      *api.ApiApp
+	userS    service.UserService
+	authS    service.AuthService
+	lockoutS service.LockoutService
 }
 
-func NewUserHandler(app *api.ApiApp, user service.UserService, auth service.AuthService) *UserHandler {
-	return &UserHandler{app, user, auth}
+func NewUserHandler(app *api.ApiApp, user service.UserService, auth service.AuthService, lockout service.LockoutService) *UserHandler {
+	return &UserHandler{app, user, auth, lockout}
 }
 
 func (h *UserHandler) Create(c *fiber.Ctx) error {
@@ -61,21 +62,14 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 	dbctx.Set(ctx, conn, tx)
 
 	// Create user
-	user, err := h.userS.CreateUser(dbctx, req, userData.Code, userData.Role)
+	user, err := h.userS.CreateUser(dbctx, req, userData.Code)
 	if err != nil {
 		tx.Rollback(ctx)
 		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
-	// Set / get otp user
-	otpToken, err := h.authS.GenerateOTPToken(dbctx, c.Get("X-Channel"), user.Email, user.Phone)
-	if err != nil {
-		tx.Rollback(ctx)
-		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
-	}
-
-	// Send otp to queue mail
-	err = h.authS.SendOTPToken(h.app.RabbitMQ, user.Email, c.Get("X-Channel"), utils.MAIL_FOR_USERACTIVATION, otpToken, model.OTP_VIA_EMAIL)
+	// Send invitation to set password
+	err = h.userS.InviteUser(dbctx, user, middleware.Client(c), userData.Code)
 	if err != nil {
 		tx.Rollback(ctx)
 		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
@@ -90,7 +84,7 @@ func (h *UserHandler) Create(c *fiber.Ctx) error {
 
 	// Set response
 	var response responses.RegisterResponse
-	response.Transform(user, otpToken)
+	response.Transform(user)
 
 	return utils.APIResponse(c, "success", fiber.StatusOK, "success", response)
 }
@@ -179,12 +173,6 @@ func (h *UserHandler) Update(c *fiber.Ctx) error {
 		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
 	}
 
-	// Define code
-	code := c.Params("code")
-	if *req.IsProfile {
-		code = userData.Code
-	}
-
 	// Set Tx transaction
 	tx, err := h.app.DB.Begin(ctx)
 	if err != nil {
@@ -196,7 +184,7 @@ func (h *UserHandler) Update(c *fiber.Ctx) error {
 	dbctx.Set(ctx, conn, tx)
 
 	// Update role
-	user, err := h.userS.UpdateUser(dbctx, req, userData.Code, code)
+	user, err := h.userS.UpdateUser(dbctx, req, userData.Code, c.Params("code"))
 	if err != nil {
 		tx.Rollback(ctx)
 		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
@@ -257,3 +245,158 @@ func (h *UserHandler) Delete(c *fiber.Ctx) error {
 
 	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
 }
+
+func (h *UserHandler) ForceLogout(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Find user
+	user, err := h.userS.FindUser(dbctx, c.Params("code"))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Logout every session of user
+	err = h.authS.LogoutAll(dbctx, user.ID, user.Code)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *UserHandler) ResendInvitation(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Replace pending invitation and send it again
+	err = h.userS.ResendInvitation(dbctx, c.Params("code"), middleware.Client(c), userData.Code)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *UserHandler) RevokeInvitation(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := h.app.DB.Begin(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Revoke pending invitation
+	err = h.userS.RevokeInvitation(dbctx, c.Params("code"))
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
+
+func (h *UserHandler) Unlock(c *fiber.Ctx) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := h.app.DB.Acquire(ctx)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+	defer conn.Release()
+
+	// Get user code (handler by)
+	userData, err := utils.ExtractTokenMetadata(c)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	// Find user
+	user, err := h.userS.FindUser(dbctx, c.Params("code"))
+	if err != nil {
+		return utils.APIResponse(c, db.ParseErr(err), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
+	}
+
+	// Unlock every login identifier of user
+	err = h.lockoutS.Unlock(dbctx, []string{user.Email, user.Phone}, userData.Code)
+	if err != nil {
+		return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+	}
+
+	return utils.APIResponse(c, "success", fiber.StatusOK, "success", nil)
+}
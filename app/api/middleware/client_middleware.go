@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fiber-starter/app/api"
+	"fiber-starter/app/model"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	clientLocalKey  = "client"
+	channelLocalKey = "channel"
+)
+
+var (
+	clientApp *api.ApiApp
+	clientS   service.ApiClientService
+)
+
+// SetupClient func to set resources used to authenticate api clients.
+func SetupClient(app *api.ApiApp, client service.ApiClientService) {
+	clientApp = app
+	clientS = client
+}
+
+// ClientProtected func for specify routes only accepting registered api clients
+// identified by X-CLIENT-ID and X-CLIENT-SECRET.
+func ClientProtected() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		// Set context
+		ctx := context.Background()
+		conn, err := clientApp.DB.Acquire(ctx)
+		if err != nil {
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+		defer conn.Release()
+
+		// Set db context
+		var dbctx db.DBCtx
+		dbctx.Set(ctx, conn, nil)
+
+		// Authenticate client
+		client, err := clientS.Authenticate(dbctx, c.Get("X-CLIENT-ID"), c.Get("X-CLIENT-SECRET"))
+		if err != nil {
+			if errors.Is(err, service.ErrInvalidClient) {
+				return utils.APIResponse(c, err.Error(), fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+			}
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+
+		c.Locals(clientLocalKey, client)
+		c.Locals(channelLocalKey, client.Channel)
+
+		return c.Next()
+	}
+}
+
+// ChannelOnly func for specify routes allowed only for clients of the channel.
+func ChannelOnly(channel string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		if Channel(c) != channel {
+			return utils.APIResponse(c, "channel is not allowed", fiber.StatusForbidden, fiber.ErrForbidden.Error(), nil)
+		}
+
+		return c.Next()
+	}
+}
+
+// Client func to get the api client authenticated for the request.
+func Client(c *fiber.Ctx) model.ApiClient {
+	client, _ := c.Locals(clientLocalKey).(model.ApiClient)
+	return client
+}
+
+// Channel func to get the channel resolved from the authenticated api client.
+func Channel(c *fiber.Ctx) string {
+	channel, _ := c.Locals(channelLocalKey).(string)
+	return channel
+}
@@ -1,11 +1,9 @@
 package middleware
 
 import (
-	"fiber-starter/pkg/utils"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/basicauth"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 )
@@ -22,7 +20,10 @@ func FiberMiddleware(app *fiber.App) {
 		"X-SIGNATURE",
 		"X-TIMESTAMPT",
 		"X-CHANNEL",
+		"X-CLIENT-ID",
+		"X-CLIENT-SECRET",
 		"X-PLAYER",
+		"X-DEVICE-NAME",
 		"Access-Control-Allow-Headers",
 		"X-Requested-With",
 		"application/json",
@@ -41,10 +42,13 @@ func FiberMiddleware(app *fiber.App) {
 	)
 }
 
-func ChannelAppOnly() func(*fiber.Ctx) error {
-	return basicauth.New(basicauth.Config{
-		Next: func(c *fiber.Ctx) bool {
-			return c.Get("X-Channel") == utils.ChannelApp
-		},
-	})
+// Deprecated func for specify routes kept as aliases of the successor path, answered with
+// the Deprecation and Link headers.
+func Deprecated(successor string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		c.Set("Deprecation", "true")
+		c.Set(fiber.HeaderLink, "<"+successor+">; rel=\"successor-version\"")
+
+		return c.Next()
+	}
 }
@@ -1,57 +1,85 @@
 package middleware
 
 import (
+	"context"
+	"errors"
+	"fiber-starter/app/api"
 	"fiber-starter/app/model"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
 	"fiber-starter/pkg/utils"
-	"os"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/basicauth"
-	jwtMiddleware "github.com/gofiber/jwt/v2"
 )
 
-// JWTProtected func for specify routes group with JWT authentication.
-// See: https://github.com/gofiber/jwt
-func JWTProtected() func(*fiber.Ctx) error {
-	// Create config for JWT authentication middleware.
-	config := jwtMiddleware.Config{
-		SigningKey:   []byte(os.Getenv("APP_KEY")),
-		ContextKey:   "jwt", // used in private routes
-		ErrorHandler: jwtError,
-	}
+var (
+	sessionApp *api.ApiApp
+	sessionS   service.SessionService
+)
 
-	return jwtMiddleware.New(config)
+// SetupSession func to set resources used to track and deny login sessions of access tokens.
+func SetupSession(app *api.ApiApp, session service.SessionService) {
+	sessionApp = app
+	sessionS = session
 }
 
-// Role admin protection
-func JWTRoleAdmin() func(*fiber.Ctx) error {
-	return basicauth.New(basicauth.Config{
-		Next: func(c *fiber.Ctx) bool {
-			tokenMetaData, err := utils.ExtractTokenMetadata(c)
+// JWTProtected func for specify routes group with JWT authentication.
+// Tokens are verified by their kid against the configured signing keys
+// and rejected when revoked.
+func JWTProtected() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		tokenMetaData, err := utils.ExtractTokenMetadata(c)
+		if err != nil {
+			return jwtError(c, err)
+		}
+		if tokenMetaData == nil {
+			return jwtError(c, utils.ErrMissingJWT)
+		}
+
+		// Touch login session of the token
+		if sessionS != nil && len(tokenMetaData.Session) > 0 {
+			err := touchSession(tokenMetaData.Session)
+			if errors.Is(err, service.ErrSessionRevoked) {
+				return jwtError(c, err)
+			}
 			if err != nil {
-				return false
+				return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
 			}
-			return tokenMetaData.Role == model.ROLE_ADMIN
-		},
-	})
+		}
+		c.Locals("jwt", tokenMetaData) // used in private routes
+
+		return c.Next()
+	}
 }
 
-// Role customer protection
-func JWTRoleCust() func(*fiber.Ctx) error {
-	return basicauth.New(basicauth.Config{
-		Next: func(c *fiber.Ctx) bool {
-			tokenMetaData, err := utils.ExtractTokenMetadata(c)
-			if err != nil {
-				return false
-			}
-			return tokenMetaData.Role == model.ROLE_CUST
-		},
-	})
+// Device func to get the device of the request, named by the X-DEVICE-NAME header.
+func Device(c *fiber.Ctx) model.Device {
+	return model.Device{
+		Name:      c.Get("X-DEVICE-NAME"),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		IP:        c.IP(),
+	}
+}
+
+func touchSession(family string) error {
+	// Set context
+	ctx := context.Background()
+	conn, err := sessionApp.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, nil)
+
+	return sessionS.Touch(dbctx, family)
 }
 
 func jwtError(c *fiber.Ctx, err error) error {
 	// Return status 401 and failed authentication error.
-	if err.Error() == "Missing or malformed JWT" {
+	if errors.Is(err, utils.ErrMissingJWT) {
 		return utils.APIResponse(c, err.Error(), fiber.StatusBadRequest, fiber.ErrBadRequest.Error(), nil)
 	}
 
@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"context"
+	"fiber-starter/app/api"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"sort"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	permissionApp       *api.ApiApp
+	permissionS         service.PermissionService
+	permissionCatalogue []string
+)
+
+// SetupPermission func to set resources used to resolve the caller's permissions.
+func SetupPermission(app *api.ApiApp, permission service.PermissionService) {
+	permissionApp = app
+	permissionS = permission
+}
+
+// RequirePermission func for specify routes allowed only when the caller's role
+// is granted the permission, must be registered after JWTProtected.
+func RequirePermission(permission string) func(*fiber.Ctx) error {
+	registerPermission(permission)
+
+	return func(c *fiber.Ctx) error {
+		// Token metadata verified by JWTProtected
+		tokenMetaData, ok := c.Locals("jwt").(*utils.TokenMetaData)
+		if !ok || tokenMetaData == nil {
+			return jwtError(c, utils.ErrMissingJWT)
+		}
+
+		// Set context
+		ctx := context.Background()
+		conn, err := permissionApp.DB.Acquire(ctx)
+		if err != nil {
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+		defer conn.Release()
+
+		// Set db context
+		var dbctx db.DBCtx
+		dbctx.Set(ctx, conn, nil)
+
+		// Get permissions of role
+		permissions, err := permissionS.FindRolePermissions(dbctx, tokenMetaData.Role)
+		if err != nil {
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+
+		arrStr := new(common.ArrStr)
+		if granted, _ := arrStr.InArray(permission, permissions); !granted {
+			return utils.APIResponse(c, "permission denied", fiber.StatusForbidden, fiber.ErrForbidden.Error(), nil)
+		}
+
+		return c.Next()
+	}
+}
+
+// Permissions func to list permissions required by the registered routes.
+func Permissions() []string {
+	return permissionCatalogue
+}
+
+func registerPermission(permission string) {
+	arrStr := new(common.ArrStr)
+	if exists, _ := arrStr.InArray(permission, permissionCatalogue); exists {
+		return
+	}
+	permissionCatalogue = append(permissionCatalogue, permission)
+	sort.Strings(permissionCatalogue)
+}
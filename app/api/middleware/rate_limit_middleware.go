@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"fiber-starter/config"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/fiber/v2"
+)
+
+const rateLimitPrefix = "ratelimit:"
+
+// rateLimitScript count requests of the sliding window atomically, returns
+// allowed flag, requests counted in window and milliseconds until a slot frees.
+var rateLimitScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local max = tonumber(ARGV[3])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], 0, now - window)
+local count = redis.call('ZCARD', KEYS[1])
+if count >= max then
+	local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	return {0, count, tonumber(oldest[2]) + window - now}
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[4])
+redis.call('PEXPIRE', KEYS[1], window)
+return {1, count + 1, 0}
+`)
+
+var (
+	rateLimitStore *redis.Client
+	rateLimitCfg   config.RateLimitConfig
+)
+
+// SetupRateLimit func to set the store shared across instances and the configured rules.
+func SetupRateLimit(store *redis.Client, cfg config.RateLimitConfig) {
+	rateLimitStore = store
+	rateLimitCfg = cfg
+}
+
+// RateLimit func for limit requests of the routes by the named rule, a rule
+// missing from config or with max 0 is not limited.
+func RateLimit(name string) func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		rule, ok := rateLimitCfg.Rules[name]
+		if !ok || rule.Max <= 0 {
+			return c.Next()
+		}
+
+		// Count request in sliding window
+		now := time.Now()
+		key := fmt.Sprintf("%s%s:%s", rateLimitPrefix, name, rateLimitKey(c, rule.Key))
+		nonce, err := utils.RandomString(8, "0123456789abcdef")
+		if err != nil {
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+		member := fmt.Sprintf("%d-%s", now.UnixNano(), nonce)
+		result, err := rateLimitScript.Run(context.Background(), rateLimitStore, []string{key},
+			now.UnixNano()/int64(time.Millisecond), rule.Window.Milliseconds(), rule.Max, member).Int64Slice()
+		if err != nil {
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+
+		remaining := int64(rule.Max) - result[1]
+		if remaining < 0 {
+			remaining = 0
+		}
+		c.Set("X-RateLimit-Limit", strconv.Itoa(rule.Max))
+		c.Set("X-RateLimit-Remaining", strconv.FormatInt(remaining, 10))
+
+		if result[0] == 0 {
+			retryAfter := time.Duration(result[2]) * time.Millisecond
+			return utils.APIResponseTooManyRequests(c, "too many requests", retryAfter)
+		}
+
+		return c.Next()
+	}
+}
+
+// rateLimitKey resolve the counter of the caller, user and channel fallback to ip when absent
+func rateLimitKey(c *fiber.Ctx, key string) string {
+	switch key {
+	case config.RATE_LIMIT_KEY_USER:
+		if tokenMetaData, err := utils.ExtractTokenMetadata(c); err == nil && tokenMetaData != nil {
+			return "user:" + tokenMetaData.Code
+		}
+	case config.RATE_LIMIT_KEY_CHANNEL:
+		if channel := Channel(c); len(channel) > 0 {
+			return "channel:" + channel
+		}
+	}
+
+	return "ip:" + c.IP()
+}
@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"context"
+	"fiber-starter/config"
+	"fiber-starter/pkg/utils"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/fiber/v2"
+)
+
+const signatureNoncePrefix = "signature:nonce:"
+
+var (
+	signatureStore *redis.Client
+	signatureCfg   config.SignatureConfig
+)
+
+// SetupSignature func to set channel secrets and the store of used signatures.
+func SetupSignature(store *redis.Client, cfg config.SignatureConfig) {
+	signatureStore = store
+	signatureCfg = cfg
+}
+
+// SignatureProtected func for specify routes only accepting requests signed with
+// the secret of the client channel through X-SIGNATURE and X-TIMESTAMPT (unix seconds),
+// must be registered after ClientProtected.
+func SignatureProtected() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		channel := Channel(c)
+		signature := c.Get("X-SIGNATURE")
+		timestamp := c.Get("X-TIMESTAMPT")
+		if len(signature) <= 0 || len(timestamp) <= 0 {
+			return utils.APIResponse(c, "missing request signature", fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+		}
+
+		secret := signatureCfg.Secrets[channel]
+		if len(secret) <= 0 {
+			return utils.APIResponse(c, "invalid channel", fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+		}
+
+		// Check timestamp within clock skew
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			return utils.APIResponse(c, "invalid request timestamp", fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+		}
+		skew := time.Since(time.Unix(unix, 0))
+		if skew > signatureCfg.ClockSkew || skew < -signatureCfg.ClockSkew {
+			return utils.APIResponse(c, "request timestamp expired", fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+		}
+
+		// Check signature
+		canonical := utils.SignatureCanonical(c.Method(), c.OriginalURL(), timestamp, c.Body())
+		if !utils.VerifySignature(secret, canonical, signature) {
+			return utils.APIResponse(c, "invalid request signature", fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+		}
+
+		// Reject replay, a request is accepted once while its timestamp is valid,
+		// keyed by the expected signature so any case of the hex is the same request
+		nonce := utils.SignRequest(secret, canonical)
+		fresh, err := signatureStore.SetNX(context.Background(), signatureNoncePrefix+channel+":"+nonce, 1, 2*signatureCfg.ClockSkew).Result()
+		if err != nil {
+			return utils.APIResponse(c, err.Error(), fiber.StatusInternalServerError, fiber.ErrInternalServerError.Error(), nil)
+		}
+		if !fresh {
+			return utils.APIResponse(c, "request already processed", fiber.StatusUnauthorized, fiber.ErrUnauthorized.Error(), nil)
+		}
+
+		return c.Next()
+	}
+}
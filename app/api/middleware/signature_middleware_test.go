@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"bufio"
+	"fiber-starter/config"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/gofiber/fiber/v2"
+)
+
+// startTestRedis serve the SET NX used for the nonces, enough of redis for the signature store
+func startTestRedis(t *testing.T) *redis.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	var mu sync.Mutex
+	keys := map[string]bool{}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				for {
+					args, err := readTestRedisCommand(reader)
+					if err != nil {
+						return
+					}
+
+					reply := "+OK\r\n"
+					if strings.EqualFold(args[0], "set") {
+						nx := false
+						for _, arg := range args[3:] {
+							nx = nx || strings.EqualFold(arg, "nx")
+						}
+						mu.Lock()
+						if nx && keys[args[1]] {
+							reply = "$-1\r\n"
+						} else {
+							keys[args[1]] = true
+						}
+						mu.Unlock()
+					}
+					conn.Write([]byte(reply))
+				}
+			}(conn)
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{Addr: listener.Addr().String()})
+	t.Cleanup(func() { client.Close() })
+
+	return client
+}
+
+func readTestRedisCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil || count <= 0 {
+		return nil, fmt.Errorf("invalid command %q", line)
+	}
+
+	args := make([]string, count)
+	for i := range args {
+		if _, err := reader.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		arg, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		args[i] = strings.TrimSuffix(arg, "\r\n")
+	}
+
+	return args, nil
+}
+
+func newSignatureTestApp(t *testing.T) *fiber.App {
+	SetupSignature(startTestRedis(t), config.SignatureConfig{
+		Secrets:   map[string]string{"app": "app-secret", "web": ""},
+		ClockSkew: time.Minute,
+	})
+
+	app := fiber.New()
+	app.Post("/auth/login", func(c *fiber.Ctx) error {
+		c.Locals(channelLocalKey, c.Get("X-CHANNEL"))
+		return c.Next()
+	}, SignatureProtected(), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+
+	return app
+}
+
+func testSignatureRequest(t *testing.T, app *fiber.App, channel, secret, timestamp, body string) int {
+	return testSignedRequest(t, app, channel, timestamp, body, utils.SignRequest(secret, utils.SignatureCanonical(fiber.MethodPost, "/auth/login?lang=en", timestamp, []byte(body))))
+}
+
+func testSignedRequest(t *testing.T, app *fiber.App, channel, timestamp, body, signature string) int {
+	request := httptest.NewRequest(fiber.MethodPost, "/auth/login?lang=en", strings.NewReader(body))
+	request.Header.Set("X-CHANNEL", channel)
+	request.Header.Set("X-TIMESTAMPT", timestamp)
+	request.Header.Set("X-SIGNATURE", signature)
+
+	response, err := app.Test(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return response.StatusCode
+}
+
+func TestSignatureProtectedRejectsReplay(t *testing.T) {
+	app := newSignatureTestApp(t)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+
+	if status := testSignatureRequest(t, app, "app", "app-secret", now, `{"email":"user@sample.com"}`); status != fiber.StatusOK {
+		t.Fatalf("signed request: status %d", status)
+	}
+	if status := testSignatureRequest(t, app, "app", "app-secret", now, `{"email":"user@sample.com"}`); status != fiber.StatusUnauthorized {
+		t.Errorf("replayed request: status %d", status)
+	}
+
+	// Another request signed at the same time is fresh
+	if status := testSignatureRequest(t, app, "app", "app-secret", now, `{"email":"other@sample.com"}`); status != fiber.StatusOK {
+		t.Errorf("other request: status %d", status)
+	}
+}
+
+func TestSignatureProtectedRejectsUpperCaseReplay(t *testing.T) {
+	app := newSignatureTestApp(t)
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	body := `{"email":"user@sample.com"}`
+	signature := utils.SignRequest("app-secret", utils.SignatureCanonical(fiber.MethodPost, "/auth/login?lang=en", now, []byte(body)))
+
+	if status := testSignedRequest(t, app, "app", now, body, signature); status != fiber.StatusOK {
+		t.Fatalf("signed request: status %d", status)
+	}
+	if status := testSignedRequest(t, app, "app", now, body, strings.ToUpper(signature)); status != fiber.StatusUnauthorized {
+		t.Errorf("upper case replay: status %d", status)
+	}
+}
+
+func TestSignatureProtectedRejectsInvalid(t *testing.T) {
+	app := newSignatureTestApp(t)
+	now := time.Now()
+	unix := func(at time.Time) string { return strconv.FormatInt(at.Unix(), 10) }
+
+	tests := []struct {
+		name      string
+		channel   string
+		secret    string
+		timestamp string
+	}{
+		{"secret", "app", "web-secret", unix(now)},
+		{"channel without secret", "web", "", unix(now)},
+		{"expired", "app", "app-secret", unix(now.Add(-2 * time.Minute))},
+		{"future", "app", "app-secret", unix(now.Add(2 * time.Minute))},
+		{"timestamp", "app", "app-secret", "yesterday"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if status := testSignatureRequest(t, app, tt.channel, tt.secret, tt.timestamp, `{}`); status != fiber.StatusUnauthorized {
+				t.Errorf("status %d", status)
+			}
+		})
+	}
+}
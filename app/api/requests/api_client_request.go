@@ -0,0 +1,26 @@
+package requests
+
+type (
+	ApiClientCreateRequest struct {
+		Name         string   `json:"name" validate:"required"`
+		Channel      string   `json:"channel" validate:"required,oneof=app web"`
+		AllowedRoles []string `json:"allowed_roles"`
+		CallbackURLs []string `json:"callback_urls" validate:"dive,url"`
+		Scopes       []string `json:"scopes"`
+		Status       *bool    `json:"status" validate:"required"`
+	}
+
+	ApiClientUpdateRequest struct {
+		Name         string   `json:"name" validate:"required"`
+		Channel      string   `json:"channel" validate:"required,oneof=app web"`
+		AllowedRoles []string `json:"allowed_roles"`
+		CallbackURLs []string `json:"callback_urls" validate:"dive,url"`
+		Scopes       []string `json:"scopes"`
+		Status       *bool    `json:"status" validate:"required"`
+		Version      int      `json:"version" validate:"required"`
+	}
+
+	ApiClientSecretRequest struct {
+		Version int `json:"version" validate:"required"`
+	}
+)
@@ -24,8 +24,48 @@ type (
 		ConfirmPassword string `json:"confirm_password" validate:"required"`
 	}
 
+	RefreshTokenRequest struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+	}
+
+	MFAVerifyRequest struct {
+		MFAToken string `json:"mfa_token" validate:"required"`
+		Code     string `json:"code" validate:"required"`
+	}
+
+	MFACodeRequest struct {
+		Code string `json:"code" validate:"required"`
+	}
+
+	PasswordChangeRequest struct {
+		PasswordChangeToken string `json:"password_change_token" validate:"required"`
+		Password            string `json:"password" validate:"required"`
+		ConfirmPassword     string `json:"confirm_password" validate:"required"`
+	}
+
+	AcceptInvitationRequest struct {
+		Token           string `json:"token" validate:"required"`
+		Password        string `json:"password" validate:"required"`
+		ConfirmPassword string `json:"confirm_password" validate:"required"`
+	}
+
+	LogoutRequest struct {
+		RefreshToken string `json:"refresh_token"`
+	}
+
 	ValidateOTPTokenRequest struct {
 		EmailPhone string `json:"email_phone" validate:"required"`
 		OTPToken   string `json:"otp_token" validate:"required"`
 	}
+
+	OIDCCallbackRequest struct {
+		Code  string `json:"code" validate:"required"`
+		State string `json:"state" validate:"required"`
+		Phone string `json:"phone"`
+	}
+
+	OIDCSignupRequest struct {
+		SignupToken string `json:"signup_token" validate:"required"`
+		Phone       string `json:"phone" validate:"required"`
+	}
 )
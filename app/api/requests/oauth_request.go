@@ -0,0 +1,31 @@
+package requests
+
+type (
+	OAuthAuthorizeRequest struct {
+		ResponseType        string `json:"response_type" query:"response_type" validate:"required,eq=code"`
+		ClientID            string `json:"client_id" query:"client_id" validate:"required"`
+		RedirectURI         string `json:"redirect_uri" query:"redirect_uri" validate:"required"`
+		Scope               string `json:"scope" query:"scope"`
+		State               string `json:"state" query:"state"`
+		CodeChallenge       string `json:"code_challenge" query:"code_challenge"`
+		CodeChallengeMethod string `json:"code_challenge_method" query:"code_challenge_method" validate:"omitempty,oneof=S256 plain"`
+	}
+
+	OAuthTokenRequest struct {
+		GrantType    string `json:"grant_type" form:"grant_type" validate:"required"`
+		Code         string `json:"code" form:"code"`
+		RedirectURI  string `json:"redirect_uri" form:"redirect_uri"`
+		CodeVerifier string `json:"code_verifier" form:"code_verifier"`
+		RefreshToken string `json:"refresh_token" form:"refresh_token"`
+		Scope        string `json:"scope" form:"scope"`
+		ClientID     string `json:"client_id" form:"client_id"`
+		ClientSecret string `json:"client_secret" form:"client_secret"`
+	}
+
+	OAuthTokenActionRequest struct {
+		Token         string `json:"token" form:"token" validate:"required"`
+		TokenTypeHint string `json:"token_type_hint" form:"token_type_hint"`
+		ClientID      string `json:"client_id" form:"client_id"`
+		ClientSecret  string `json:"client_secret" form:"client_secret"`
+	}
+)
@@ -11,4 +11,9 @@ type (
 		Status  *bool  `json:"status" validate:"required"`
 		Version int    `json:"version" validate:"required"`
 	}
+
+	RolePermissionRequest struct {
+		Permissions []string `json:"permissions" validate:"required,min=1"`
+		Version     int      `json:"version" validate:"required"`
+	}
 )
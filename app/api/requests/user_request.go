@@ -11,12 +11,48 @@ type (
 	}
 
 	UserUpdateRequest struct {
-		IsProfile *bool  `json:"is_profile" validate:"required"`
-		Name      string `json:"name" validate:"required"`
-		Phone     string `json:"phone" validate:"required"`
-		Address   string `json:"address"`
-		Img       string `json:"img"`
-		Status    *bool  `json:"status"`
-		Version   int    `json:"version" validate:"required"`
+		Name    string `json:"name" validate:"required"`
+		Phone   string `json:"phone" validate:"required"`
+		Address string `json:"address"`
+		Img     string `json:"img"`
+		Status  *bool  `json:"status"`
+		Version int    `json:"version" validate:"required"`
+	}
+
+	ProfileUpdateRequest struct {
+		Name    *string `json:"name" validate:"omitempty,min=1"`
+		Address *string `json:"address"`
+		Img     *string `json:"img"`
+		Version int     `json:"version" validate:"required"`
+	}
+
+	ProfileChangePasswordRequest struct {
+		CurrentPassword string `json:"current_password" validate:"required"`
+		Password        string `json:"password" validate:"required"`
+		ConfirmPassword string `json:"confirm_password" validate:"required"`
+	}
+
+	ProfileChangeEmailRequest struct {
+		Email    string `json:"email" validate:"required,email"`
+		Password string `json:"password" validate:"required"`
+	}
+
+	ProfileChangePhoneRequest struct {
+		Phone string `json:"phone" validate:"required"`
+	}
+
+	ProfileVerifyChangeRequest struct {
+		EmailPhone string `json:"email_phone"`
+		OTPToken   string `json:"otp_token" validate:"required"`
+	}
+
+	NotificationPreferenceRequest struct {
+		Preferences []NotificationPreferenceItem `json:"preferences" validate:"required,min=1,dive"`
+	}
+
+	NotificationPreferenceItem struct {
+		Type    string `json:"type" validate:"required"`
+		Channel string `json:"channel" validate:"required"`
+		Enabled *bool  `json:"enabled" validate:"required"`
 	}
 )
@@ -0,0 +1,53 @@
+package responses
+
+import (
+	"fiber-starter/app/model"
+)
+
+type ApiClientResponse struct {
+	Name         string   `json:"name"`
+	Code         string   `json:"code"`
+	ClientID     string   `json:"client_id"`
+	ClientSecret string   `json:"client_secret,omitempty"`
+	Channel      string   `json:"channel"`
+	AllowedRoles []string `json:"allowed_roles"`
+	CallbackURLs []string `json:"callback_urls"`
+	Scopes       []string `json:"scopes"`
+	Status       bool     `json:"status"`
+	Version      int      `json:"version"`
+	CreatedDate  string   `json:"created_date"`
+	CreatedBy    string   `json:"created_by"`
+	UpdatedDate  string   `json:"updated_date"`
+	UpdatedBy    string   `json:"updated_by"`
+}
+
+// Transform set response of client, secret is only given once when created or rotated
+func (r *ApiClientResponse) Transform(data model.ApiClient, secret string) {
+	r.Name = data.Name
+	r.Code = data.Code
+	r.ClientID = data.ClientID
+	r.ClientSecret = secret
+	r.Channel = data.Channel
+	r.AllowedRoles = data.AllowedRoles
+	r.CallbackURLs = data.CallbackURLs
+	r.Scopes = data.Scopes
+	r.Status = data.Status
+	r.Version = int(data.Version)
+	r.CreatedBy = data.CreatedBy
+	r.UpdatedBy = data.UpdatedBy.String
+	if r.AllowedRoles == nil {
+		r.AllowedRoles = []string{}
+	}
+	if r.CallbackURLs == nil {
+		r.CallbackURLs = []string{}
+	}
+	if r.Scopes == nil {
+		r.Scopes = []string{}
+	}
+	if !data.CreatedDate.IsZero() {
+		r.CreatedDate = data.CreatedDate.Format("2006-01-02 15:04:05")
+	}
+	if data.UpdatedDate.Valid {
+		r.UpdatedDate = data.UpdatedDate.Time.Format("2006-01-02 15:04:05")
+	}
+}
@@ -10,50 +10,98 @@ type RegisterResponse struct {
 	Phone string `json:"phone"`
 	Code  string `json:"code"`
 	Role  string `json:"role"`
-	OTP   string `json:"otp"`
 }
 
-func (r *RegisterResponse) Transform(data model.User, otpToken string) {
+// Transform the activation otp or link is only delivered to the email, never answered
+func (r *RegisterResponse) Transform(data model.User) {
 	r.Name = data.Name
 	r.Email = data.Email
 	r.Phone = data.Phone
 	r.Code = data.Code
 	r.Role = data.Role
-	r.OTP = otpToken
 }
 
 type LoginResponse struct {
-	Name   string `json:"name"`
-	Email  string `json:"email"`
-	Phone  string `json:"phone"`
-	Code   string `json:"code"`
-	Token  string `json:"token"`
-	Role   string `json:"role"`
-	Img    string `json:"img"`
-	Status bool   `json:"status"`
-	OTP    string `json:"otp"`
-}
-
-func (r *LoginResponse) Transform(data model.User, otpToken string) {
+	Name                   string `json:"name"`
+	Email                  string `json:"email"`
+	Phone                  string `json:"phone"`
+	Code                   string `json:"code"`
+	Token                  string `json:"token"`
+	ExpiresAt              int64  `json:"expires_at"`
+	RefreshToken           string `json:"refresh_token"`
+	RefreshExpiresAt       int64  `json:"refresh_expires_at"`
+	MFARequired            bool   `json:"mfa_required"`
+	MFAToken               string `json:"mfa_token"`
+	PasswordChangeRequired bool   `json:"password_change_required"`
+	PasswordChangeToken    string `json:"password_change_token"`
+	Role                   string `json:"role"`
+	Img                    string `json:"img"`
+	Status                 bool   `json:"status"`
+}
+
+func (r *LoginResponse) Transform(data model.User, token model.AuthToken) {
 	r.Name = data.Name
 	r.Email = data.Email
 	r.Phone = data.Phone
 	r.Code = data.Code
-	r.Token = data.RememberToken.String
+	r.Token = token.AccessToken
+	r.ExpiresAt = token.AccessExpires
+	r.RefreshToken = token.RefreshToken
+	r.RefreshExpiresAt = token.RefreshExpires
+	r.MFARequired = len(token.MFAToken) > 0
+	r.MFAToken = token.MFAToken
+	r.PasswordChangeRequired = len(token.PasswordChangeToken) > 0
+	r.PasswordChangeToken = token.PasswordChangeToken
 	r.Role = data.Role
 	r.Img = data.Img.String
 	r.Status = data.Status
-	r.OTP = otpToken
 }
 
+// SendOTPTokenResponse the otp or link is only delivered to the email or phone, never answered
 type SendOTPTokenResponse struct {
-	OTPToken   string `json:"otp_token"`
 	Status     bool   `json:"status"`
 	EmailPhone string `json:"email_phone"`
 }
 
-func (r *SendOTPTokenResponse) Transform(otpToken string, status bool, emailPhone string) {
-	r.OTPToken = otpToken
+func (r *SendOTPTokenResponse) Transform(status bool, emailPhone string) {
 	r.Status = status
 	r.EmailPhone = emailPhone
 }
+
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+func (r *MFAEnrollResponse) Transform(secret, provisioningURI string) {
+	r.Secret = secret
+	r.ProvisioningURI = provisioningURI
+}
+
+type MFARecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+func (r *MFARecoveryCodesResponse) Transform(recoveryCodes []string) {
+	r.RecoveryCodes = recoveryCodes
+}
+
+type OIDCAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"state"`
+}
+
+func (r *OIDCAuthorizeResponse) Transform(authorizationURL, state string) {
+	r.AuthorizationURL = authorizationURL
+	r.State = state
+}
+
+type OIDCSignupResponse struct {
+	SignupRequired bool   `json:"signup_required"`
+	SignupToken    string `json:"signup_token"`
+}
+
+func (r *OIDCSignupResponse) Transform(signupToken string) {
+	r.SignupRequired = true
+	r.SignupToken = signupToken
+}
@@ -0,0 +1,24 @@
+package responses
+
+import (
+	"fiber-starter/pkg/utils"
+	"time"
+)
+
+type MailboxMessageResponse struct {
+	ID      string    `json:"id"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Date    time.Time `json:"date"`
+	Text    string    `json:"text"`
+}
+
+func (r *MailboxMessageResponse) Transform(data utils.MailboxMessage) {
+	r.ID = data.ID
+	r.From = data.From
+	r.To = data.To
+	r.Subject = data.Subject
+	r.Date = data.Date
+	r.Text = data.Text
+}
@@ -0,0 +1,40 @@
+package responses
+
+import (
+	"fiber-starter/app/model"
+	"time"
+)
+
+type NotificationResponse struct {
+	ID          int64      `json:"id"`
+	Type        string     `json:"type"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	Read        bool       `json:"read"`
+	ReadDate    *time.Time `json:"read_date"`
+	CreatedDate time.Time  `json:"created_date"`
+}
+
+func (r *NotificationResponse) Transform(data model.Notification) {
+	r.ID = data.ID
+	r.Type = data.Type
+	r.Title = data.Title
+	r.Body = data.Body
+	r.Read = data.ReadDate.Valid
+	if data.ReadDate.Valid {
+		r.ReadDate = &data.ReadDate.Time
+	}
+	r.CreatedDate = data.CreatedDate
+}
+
+type NotificationPreferenceResponse struct {
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (r *NotificationPreferenceResponse) Transform(data model.NotificationPreference) {
+	r.Type = data.Type
+	r.Channel = data.Channel
+	r.Enabled = data.Enabled
+}
@@ -0,0 +1,80 @@
+package responses
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/pkg/utils"
+	"strings"
+)
+
+type OAuthConsentResponse struct {
+	ClientID    string   `json:"client_id"`
+	ClientName  string   `json:"client_name"`
+	RedirectURI string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes"`
+}
+
+func (r *OAuthConsentResponse) Transform(client model.ApiClient, redirectURI string, scopes []string) {
+	r.ClientID = client.ClientID
+	r.ClientName = client.Name
+	r.RedirectURI = redirectURI
+	r.Scopes = scopes
+	if r.Scopes == nil {
+		r.Scopes = []string{}
+	}
+}
+
+type OAuthRedirectResponse struct {
+	RedirectURI string `json:"redirect_uri"`
+}
+
+func (r *OAuthRedirectResponse) Transform(redirectURI string) {
+	r.RedirectURI = redirectURI
+}
+
+// OAuthTokenResponse successful token response, see RFC 6749 section 5.1
+type OAuthTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+func (r *OAuthTokenResponse) Transform(token model.AuthToken, scopes []string, now int64) {
+	r.AccessToken = token.AccessToken
+	r.TokenType = "Bearer"
+	r.ExpiresIn = token.AccessExpires - now
+	r.RefreshToken = token.RefreshToken
+	r.Scope = strings.Join(scopes, " ")
+}
+
+type OAuthErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+// OAuthIntrospectResponse token state, see RFC 7662 section 2.2
+type OAuthIntrospectResponse struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	Role      string `json:"role,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+}
+
+func (r *OAuthIntrospectResponse) Transform(data *utils.TokenMetaData, tokenType string) {
+	if data == nil {
+		return
+	}
+	r.Active = true
+	r.Scope = data.Scope
+	r.ClientID = data.ClientID
+	r.Sub = data.Code
+	r.Role = data.Role
+	r.TokenType = tokenType
+	r.Exp = data.Expires
+	r.Iat = data.IssuedAt
+}
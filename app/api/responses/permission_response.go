@@ -0,0 +1,18 @@
+package responses
+
+import "strings"
+
+type PermissionResponse struct {
+	Slug     string `json:"slug"`
+	Resource string `json:"resource"`
+	Action   string `json:"action"`
+}
+
+func (r *PermissionResponse) Transform(slug string) {
+	r.Slug = slug
+	r.Resource = slug
+	if i := strings.Index(slug, ":"); i >= 0 {
+		r.Resource = slug[:i]
+		r.Action = slug[i+1:]
+	}
+}
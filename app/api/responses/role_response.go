@@ -31,3 +31,20 @@ func (r *RoleResponse) Transform(data model.Role) {
 		r.UpdatedDate = data.UpdatedDate.Time.Format("2006-01-02 15:04:05")
 	}
 }
+
+type RolePermissionResponse struct {
+	Code        string   `json:"code"`
+	Slug        string   `json:"slug"`
+	Version     int      `json:"version"`
+	Permissions []string `json:"permissions"`
+}
+
+func (r *RolePermissionResponse) Transform(data model.Role, permissions []string) {
+	r.Code = data.Code
+	r.Slug = data.Slug
+	r.Version = int(data.Version)
+	r.Permissions = permissions
+	if r.Permissions == nil {
+		r.Permissions = []string{}
+	}
+}
@@ -6,18 +6,19 @@ import (
 )
 
 type UserResponse struct {
-	Name        string    `json:"name"`
-	Email       string    `json:"email"`
-	Code        string    `json:"code"`
-	Role        string    `json:"role"`
-	Phone       string    `json:"phone"`
-	Address     string    `json:"address"`
-	Img         string    `json:"img"`
-	Status      bool      `json:"status"`
-	OTP         string    `json:"otp"`
-	Version     int       `json:"version"`
-	CreatedDate time.Time `json:"created_date"`
-	UpdatedDate time.Time `json:"updated_date"`
+	Name               string    `json:"name"`
+	Email              string    `json:"email"`
+	Code               string    `json:"code"`
+	Role               string    `json:"role"`
+	Phone              string    `json:"phone"`
+	Address            string    `json:"address"`
+	Img                string    `json:"img"`
+	Status             bool      `json:"status"`
+	MustChangePassword bool      `json:"must_change_password"`
+	OTP                string    `json:"otp"`
+	Version            int       `json:"version"`
+	CreatedDate        time.Time `json:"created_date"`
+	UpdatedDate        time.Time `json:"updated_date"`
 }
 
 func (r *UserResponse) Transform(data model.User, otpToken string) {
@@ -29,8 +30,29 @@ func (r *UserResponse) Transform(data model.User, otpToken string) {
 	r.Address = data.Address.String
 	r.Img = data.Img.String
 	r.Status = data.Status
+	r.MustChangePassword = data.MustChangePassword
 	r.OTP = otpToken
 	r.Version = int(data.Version)
 	r.CreatedDate = data.CreatedDate
 	r.UpdatedDate = data.UpdatedDate.Time
 }
+
+type UserSessionResponse struct {
+	ID           int64     `json:"id"`
+	DeviceName   string    `json:"device_name"`
+	UserAgent    string    `json:"user_agent"`
+	IP           string    `json:"ip"`
+	Current      bool      `json:"current"`
+	CreatedDate  time.Time `json:"created_date"`
+	LastSeenDate time.Time `json:"last_seen_date"`
+}
+
+func (r *UserSessionResponse) Transform(data model.UserSession, currentFamily string) {
+	r.ID = data.ID
+	r.DeviceName = data.DeviceName
+	r.UserAgent = data.UserAgent
+	r.IP = data.IP
+	r.Current = data.Family == currentFamily
+	r.CreatedDate = data.CreatedDate
+	r.LastSeenDate = data.LastSeenDate
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"fiber-starter/app/api/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// DevRoutes func for describe group of development routes, only registered when enabled in debug.
+func DevRoutes(r fiber.Router, h *handlers.DevHandler) {
+	dev := r.Group("/dev")
+	dev.Get("/mailbox", h.Mailbox)
+}
@@ -3,31 +3,86 @@ package routes
 import (
 	"fiber-starter/app/api/handlers"
 	"fiber-starter/app/api/middleware"
+	"fiber-starter/app/model"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type PrivateHandlers struct {
-	User *handlers.UserHandler
-	Role *handlers.RoleHandler
+	User         *handlers.UserHandler
+	Role         *handlers.RoleHandler
+	Permission   *handlers.PermissionHandler
+	MFA          *handlers.MFAHandler
+	Session      *handlers.SessionHandler
+	Profile      *handlers.ProfileHandler
+	Notification *handlers.NotificationHandler
+	ApiClient    *handlers.ApiClientHandler
 }
 
 // PrivateRoutes func for describe group of private routes.
 func PrivateRoutes(r fiber.Router, h PrivateHandlers) {
 	// Route Role
-	role := r.Group("/role", middleware.JWTRoleAdmin())
-	role.Post("/", h.Role.Create)
-	role.Get("/", h.Role.GetList)
-	role.Get("/:code?", h.Role.Get)
-	role.Put("/:code?", h.Role.Update)
-	role.Delete("/:code?", h.Role.Delete)
-
-	// Route User
-	user := r.Group("/user", middleware.JWTProtected())
-	user.Post("/", h.User.Create)
-	user.Get("/", h.User.GetList)
-	user.Post("profile", h.User.Update)
-	user.Get("/:code?", h.User.Get)
-	user.Put("/:code?", h.User.Update)
-	user.Delete("/:code?", h.User.Delete)
+	role := r.Group("/role", middleware.JWTProtected(), middleware.RateLimit("private"))
+	role.Post("/", middleware.RequirePermission(model.PERMISSION_ROLE_CREATE), h.Role.Create)
+	role.Get("/", middleware.RequirePermission(model.PERMISSION_ROLE_READ), h.Role.GetList)
+	role.Get("/:code?", middleware.RequirePermission(model.PERMISSION_ROLE_READ), h.Role.Get)
+	role.Put("/:code?", middleware.RequirePermission(model.PERMISSION_ROLE_UPDATE), h.Role.Update)
+	role.Delete("/:code?", middleware.RequirePermission(model.PERMISSION_ROLE_DELETE), h.Role.Delete)
+	role.Get("/:code/permissions", middleware.RequirePermission(model.PERMISSION_ROLE_READ), h.Role.GetPermissions)
+	role.Post("/:code/permissions", middleware.RequirePermission(model.PERMISSION_ROLE_UPDATE), h.Role.GrantPermissions)
+	role.Delete("/:code/permissions", middleware.RequirePermission(model.PERMISSION_ROLE_UPDATE), h.Role.RevokePermissions)
+
+	// Route Permission
+	permission := r.Group("/permission", middleware.JWTProtected(), middleware.RateLimit("private"))
+	permission.Get("/", middleware.RequirePermission(model.PERMISSION_ROLE_READ), h.Permission.GetList)
+
+	// Route Profile
+	me := r.Group("/me", middleware.JWTProtected(), middleware.RateLimit("private"))
+	me.Get("/", h.Profile.Get)
+	me.Patch("/", h.Profile.Update)
+	me.Post("/change-password", h.Profile.ChangePassword)
+	me.Post("/change-email", h.Profile.ChangeEmail)
+	me.Post("/change-email/verify", h.Profile.VerifyEmail)
+	me.Post("/change-phone", h.Profile.ChangePhone)
+	me.Post("/change-phone/verify", h.Profile.VerifyPhone)
+	me.Post("/mfa", h.MFA.Enroll)
+	me.Post("/mfa/enable", h.MFA.Enable)
+	me.Delete("/mfa", h.MFA.Disable)
+	me.Get("/sessions", h.Session.GetList)
+	me.Delete("/sessions/:id", h.Session.Revoke)
+	me.Get("/notifications", h.Notification.GetList)
+	me.Post("/notifications/read", h.Notification.MarkAllRead)
+	me.Get("/notifications/preferences", h.Notification.GetPreferences)
+	me.Put("/notifications/preferences", h.Notification.UpdatePreferences)
+	me.Post("/notifications/:id/read", h.Notification.MarkRead)
+
+	// Route Profile, deprecated aliases of /me registered ahead of the user routes
+	profile := r.Group("/user/profile", middleware.JWTProtected(), middleware.RateLimit("private"))
+	profile.Post("/", middleware.Deprecated("/me"), h.Profile.Update)
+	profile.Post("/mfa", middleware.Deprecated("/me/mfa"), h.MFA.Enroll)
+	profile.Post("/mfa/enable", middleware.Deprecated("/me/mfa/enable"), h.MFA.Enable)
+	profile.Delete("/mfa", middleware.Deprecated("/me/mfa"), h.MFA.Disable)
+	profile.Get("/sessions", middleware.Deprecated("/me/sessions"), h.Session.GetList)
+	profile.Delete("/sessions/:id", middleware.Deprecated("/me/sessions/:id"), h.Session.Revoke)
+
+	// Route User, management of other users is granted by the user permissions
+	user := r.Group("/user", middleware.JWTProtected(), middleware.RateLimit("private"))
+	user.Post("/", middleware.RequirePermission(model.PERMISSION_USER_CREATE), h.User.Create)
+	user.Get("/", middleware.RequirePermission(model.PERMISSION_USER_READ), h.User.GetList)
+	user.Get("/:code?", middleware.RequirePermission(model.PERMISSION_USER_READ), h.User.Get)
+	user.Put("/:code?", middleware.RequirePermission(model.PERMISSION_USER_UPDATE), h.User.Update)
+	user.Post("/:code/logout", middleware.RequirePermission(model.PERMISSION_USER_LOGOUT), h.User.ForceLogout)
+	user.Post("/:code/unlock", middleware.RequirePermission(model.PERMISSION_USER_UNLOCK), h.User.Unlock)
+	user.Post("/:code/invitation", middleware.RequirePermission(model.PERMISSION_USER_CREATE), h.User.ResendInvitation)
+	user.Delete("/:code/invitation", middleware.RequirePermission(model.PERMISSION_USER_CREATE), h.User.RevokeInvitation)
+	user.Delete("/:code?", middleware.RequirePermission(model.PERMISSION_USER_DELETE), h.User.Delete)
+
+	// Route Api Client
+	client := r.Group("/client", middleware.JWTProtected(), middleware.RateLimit("private"))
+	client.Post("/", middleware.RequirePermission(model.PERMISSION_CLIENT_CREATE), h.ApiClient.Create)
+	client.Get("/", middleware.RequirePermission(model.PERMISSION_CLIENT_READ), h.ApiClient.GetList)
+	client.Get("/:code?", middleware.RequirePermission(model.PERMISSION_CLIENT_READ), h.ApiClient.Get)
+	client.Put("/:code?", middleware.RequirePermission(model.PERMISSION_CLIENT_UPDATE), h.ApiClient.Update)
+	client.Post("/:code/secret", middleware.RequirePermission(model.PERMISSION_CLIENT_UPDATE), h.ApiClient.RotateSecret)
+	client.Delete("/:code?", middleware.RequirePermission(model.PERMISSION_CLIENT_DELETE), h.ApiClient.Delete)
 }
@@ -3,21 +3,50 @@ package routes
 import (
 	"fiber-starter/app/api/handlers"
 	"fiber-starter/app/api/middleware"
+	"fiber-starter/pkg/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 type PublicHandlers struct {
 	Auth *handlers.AuthHandler
+	OIDC *handlers.OIDCHandler
 }
 
 // PublicRoutes func for describe group of public routes.
 func PublicRoutes(r fiber.Router, h PublicHandlers) {
 	// Route Auth
-	auth := r.Group("/auth")
-	auth.Post("/register", middleware.ChannelAppOnly(), h.Auth.Register)
+	auth := r.Group("/auth", middleware.RateLimit("auth"), middleware.SignatureProtected())
+	auth.Post("/register", middleware.ChannelOnly(utils.ChannelApp), h.Auth.Register)
 	auth.Post("/login", h.Auth.Login)
-	auth.Post("/send-otptoken/:type?", h.Auth.SendOTPToken)
+	auth.Post("/refresh", h.Auth.RefreshToken)
+	auth.Post("/mfa/verify", h.Auth.MFAVerify)
+	auth.Post("/logout", middleware.JWTProtected(), h.Auth.Logout)
+	auth.Post("/logout-all", middleware.JWTProtected(), h.Auth.LogoutAll)
+	auth.Post("/send-otptoken/:type?", middleware.RateLimit("otp"), h.Auth.SendOTPToken)
 	auth.Post("/reset-password", h.Auth.ResetPassword)
+	auth.Post("/password-change", h.Auth.PasswordChange)
+	auth.Post("/accept-invitation", h.Auth.AcceptInvitation)
+	auth.Post("/validate-otptoken/"+utils.MAIL_FOR_LOGIN, h.Auth.PasswordlessLogin)
 	auth.Post("/validate-otptoken/:type?", h.Auth.ValidateOTPToken)
+	auth.Post("/oidc/signup", h.OIDC.Signup)
+	auth.Get("/oidc/:provider", h.OIDC.Authorize)
+	auth.Post("/oidc/:provider/callback", h.OIDC.Callback)
+}
+
+// WellKnownRoutes func for describe group of discovery routes outside the API version.
+func WellKnownRoutes(r fiber.Router, h PublicHandlers) {
+	wellKnown := r.Group("/.well-known")
+	wellKnown.Get("/jwks.json", h.Auth.JWKS)
+}
+
+// OAuthRoutes func for describe group of oauth authorization server routes outside the API version.
+func OAuthRoutes(r fiber.Router, h *handlers.OAuthHandler) {
+	// Rate limited by the auth rule like the login routes, credentials and codes are guessed here too
+	oauth := r.Group("/oauth", middleware.RateLimit("auth"))
+	oauth.Get("/authorize", middleware.JWTProtected(), h.Consent)
+	oauth.Post("/authorize", middleware.JWTProtected(), h.Approve)
+	oauth.Post("/token", h.Token)
+	oauth.Post("/introspect", h.Introspect)
+	oauth.Post("/revoke", h.Revoke)
 }
@@ -14,24 +14,66 @@ func Configure(app *api.ApiApp) {
 	userR := repository.NewUserRepository()
 	roleR := repository.NewRoleRepository()
 	otpR := repository.NewUserOTPRepository()
+	refreshR := repository.NewRefreshTokenRepository()
+	permissionR := repository.NewPermissionRepository()
+	mfaR := repository.NewUserMFARepository()
+	auditR := repository.NewAuthAuditRepository()
+	clientR := repository.NewApiClientRepository()
+	identityR := repository.NewUserIdentityRepository()
+	passwordHistoryR := repository.NewPasswordHistoryRepository()
+	invitationR := repository.NewUserInvitationRepository()
+	sessionR := repository.NewUserSessionRepository()
+	notificationR := repository.NewNotificationRepository()
+	deliveryR := repository.NewNotificationDeliveryRepository()
+	preferenceR := repository.NewNotificationPreferenceRepository()
 
 	// Define Services
-	authS := service.NewAuthService(userR, roleR, otpR)
-	roleS := service.NewRoleService(roleR)
-	userS := service.NewUserService(userR, roleR, otpR)
+	mfaS := service.NewMFAService(mfaR, app.Config.MFA)
+	passwordS := service.NewPasswordService(userR, passwordHistoryR)
+	sessionS := service.NewSessionService(sessionR, refreshR, app.Redis, app.Config.JWT)
+	notificationS := service.NewNotificationService(notificationR, deliveryR, preferenceR, service.NewMailService(), app.Config.Notification)
+	authS := service.NewAuthService(userR, roleR, otpR, refreshR, mfaS, passwordS, invitationR, sessionS, notificationS)
+	permissionS := service.NewPermissionService(permissionR, app.Redis)
+	lockoutS := service.NewLockoutService(auditR, app.Redis, app.Config.Lockout)
+	roleS := service.NewRoleService(roleR, permissionR, permissionS)
+	userS := service.NewUserService(userR, roleR, otpR, invitationR, notificationS)
+	clientS := service.NewApiClientService(clientR, roleR)
+	oidcS := service.NewOIDCService(userR, roleR, identityR, authS, app.Redis)
+	profileS := service.NewProfileService(userR, passwordS, authS, notificationS)
+	oauthS := service.NewOAuthService(clientR, userR, refreshR, clientS, permissionS, app.Redis, app.Config.JWT)
 
 	// Define Handlers
-	authH := handlers.NewAuthHandler(app, authS)
+	authH := handlers.NewAuthHandler(app, authS, lockoutS)
 	roleH := handlers.NewRoleHandler(app, roleS)
-	userH := handlers.NewUserHandler(app, userS, authS)
-
-	// Define Main Route API
-	api := app.Fiber.Group(fmt.Sprintf("/api/%s", app.Config.App.Version))
+	userH := handlers.NewUserHandler(app, userS, authS, lockoutS)
+	permissionH := handlers.NewPermissionHandler(app)
+	mfaH := handlers.NewMFAHandler(app, mfaS)
+	sessionH := handlers.NewSessionHandler(app, sessionS)
+	clientH := handlers.NewApiClientHandler(app, clientS)
+	oidcH := handlers.NewOIDCHandler(app, oidcS)
+	oauthH := handlers.NewOAuthHandler(app, oauthS)
+	profileH := handlers.NewProfileHandler(app, profileS)
+	notificationH := handlers.NewNotificationHandler(app, notificationS)
+	devH := handlers.NewDevHandler(app)
 
 	// Middlewares.
 	middleware.FiberMiddleware(app.Fiber) // Register Fiber's middleware for app.
+	middleware.SetupPermission(app, permissionS)
+	middleware.SetupSession(app, sessionS)
+	middleware.SetupClient(app, clientS)
+	middleware.SetupRateLimit(app.Redis.RedisDefault, app.Config.RateLimit)
+	middleware.SetupSignature(app.Redis.RedisDefault, app.Config.Signature)
+
+	// Define Main Route API, limited per ip before the client credentials are checked,
+	// then the client is resolved so the channel key of the api rule counts per channel
+	api := app.Fiber.Group(fmt.Sprintf("/api/%s", app.Config.App.Version), middleware.RateLimit("client"), middleware.ClientProtected(), middleware.RateLimit("api"))
 
 	// Routes
-	PublicRoutes(api, PublicHandlers{authH})
-	PrivateRoutes(api, PrivateHandlers{userH, roleH})
+	WellKnownRoutes(app.Fiber, PublicHandlers{authH, oidcH})
+	OAuthRoutes(app.Fiber, oauthH)
+	if app.Config.Mail.DevMailbox {
+		DevRoutes(app.Fiber, devH)
+	}
+	PublicRoutes(api, PublicHandlers{authH, oidcH})
+	PrivateRoutes(api, PrivateHandlers{userH, roleH, permissionH, mfaH, sessionH, profileH, notificationH, clientH})
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fiber-starter/config"
 	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
 	"fmt"
 	"log"
 	"os"
@@ -39,6 +40,21 @@ func New(c *config.Config) *ApiApp {
 		log.Fatalln(err)
 	}
 
+	err = utils.SetupJWT(c.JWT, redis.RedisDefault)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	utils.SetupOIDC(c.OIDC)
+
+	err = utils.SetupPasswordPolicy(c.Password)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	err = utils.SetupHasher(c.Hash)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
 	return &ApiApp{
 		Config:    c,
 		DB:        db.Init(c),
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/urfave/cli/v2"
+)
+
+// CreateApiClientHandler register an api client from the command line, used to
+// bootstrap the first client before any admin can log in.
+func (cliApp *CliApp) CreateApiClientHandler(c *cli.Context) error {
+	channel := c.String("client")
+	if err := model.CheckValidClientChannel(channel); err != nil {
+		log.Printf("[CLI - CreateApiClientHandler] %v", err)
+		return err
+	}
+
+	// Set request
+	status := true
+	req := requests.ApiClientCreateRequest{
+		Name:    c.String("client-name"),
+		Channel: channel,
+		Status:  &status,
+	}
+	if len(req.Name) <= 0 {
+		req.Name = fmt.Sprintf("%s client", channel)
+	}
+	if roles := c.String("client-roles"); len(roles) > 0 {
+		req.AllowedRoles = strings.Split(roles, ",")
+	}
+	if callbackURL := c.String("client-callback"); len(callbackURL) > 0 {
+		req.CallbackURLs = []string{callbackURL}
+	}
+
+	// Set context
+	ctx := context.Background()
+	conn, err := cliApp.DB.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	// Set Tx transaction
+	tx, err := cliApp.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Set db context
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, conn, tx)
+
+	// Create client
+	clientS := service.NewApiClientService(repository.NewApiClientRepository(), repository.NewRoleRepository())
+	client, secret, err := clientS.CreateClient(dbctx, req, "cli", nil)
+	if err != nil {
+		tx.Rollback(ctx)
+		log.Printf("[CLI - CreateApiClientHandler] Could not create client: %v", err)
+		return err
+	}
+
+	// Commit transaction
+	err = tx.Commit(ctx)
+	if err != nil {
+		tx.Rollback(ctx)
+		return err
+	}
+
+	fmt.Printf("Client %s created for channel %s\nX-CLIENT-ID: %s\nX-CLIENT-SECRET: %s\n", client.Code, client.Channel, client.ClientID, secret)
+
+	return nil
+}
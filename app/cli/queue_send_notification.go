@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/app/service"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+	"log"
+	"os"
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+// dispatchLimit deliveries queued by one transaction of the relay
+const dispatchLimit = 100
+
+func (cliApp *CliApp) QueueSendNotificationHandler(c *cli.Context) error {
+	notificationService := cliApp.notificationService()
+
+	go cliApp.relayNotifications(notificationService)
+
+	return cliApp.consumeQueue(utils.CMDQueueSendNotification, func(body []byte) {
+		var qData utils.NotificationData
+		err := json.Unmarshal(body, &qData)
+		if err != nil {
+			log.Printf("Error decoding JSON: %s", err)
+			return
+		}
+		cliApp.deliverNotification(notificationService, qData)
+	})
+}
+
+// QueueSendMailHandler drain the mails queued before notifications, sent like email notifications without a delivery record
+func (cliApp *CliApp) QueueSendMailHandler(c *cli.Context) error {
+	notificationService := cliApp.notificationService()
+
+	return cliApp.consumeQueue(utils.CMDQueueSendMail, func(body []byte) {
+		var qData utils.MailData
+		err := json.Unmarshal(body, &qData)
+		if err != nil {
+			log.Printf("Error decoding JSON: %s", err)
+			return
+		}
+		for _, receiver := range qData.Receivers {
+			cliApp.deliverNotification(notificationService, utils.NotificationData{
+				Channel:   model.NOTIFY_CHANNEL_EMAIL,
+				Recipient: receiver,
+				Type:      qData.Usage,
+				Data:      qData.Data,
+			})
+		}
+	})
+}
+
+// QueueSendPhoneHandler drain the phone messages queued before notifications, sent like sms notifications without a delivery record
+func (cliApp *CliApp) QueueSendPhoneHandler(c *cli.Context) error {
+	notificationService := cliApp.notificationService()
+
+	return cliApp.consumeQueue(utils.CMDQueueSendPhone, func(body []byte) {
+		var qData utils.PhoneData
+		err := json.Unmarshal(body, &qData)
+		if err != nil {
+			log.Printf("Error decoding JSON: %s", err)
+			return
+		}
+		cliApp.deliverNotification(notificationService, utils.NotificationData{
+			Channel:   model.NOTIFY_CHANNEL_SMS,
+			Recipient: qData.Receiver,
+			Type:      qData.Usage,
+			Data:      qData.Data,
+		})
+	})
+}
+
+// consumeQueue handle every message of the queue then acknowledge it, until the program terminates
+func (cliApp *CliApp) consumeQueue(qName string, handle func(body []byte)) error {
+	conn := cliApp.RabbitMQ.RabbitMQConnection
+	ch := cliApp.RabbitMQ.RabbitMQChannel
+	defer conn.Close()
+	defer ch.Close()
+	defer utils.CloseMail()
+
+	queue, err := ch.QueueDeclare(qName, true, false, false, false, nil)
+	if err != nil {
+		log.Fatalf("[CLI - %s] %s: %v", qName, "Could not declare `add` queue", err)
+		return err
+	}
+
+	err = ch.Qos(1, 0, false)
+	if err != nil {
+		log.Fatalf("[CLI - %s] %s: %v", qName, "Could not configure QoS", err)
+		return err
+	}
+
+	msgCh, err := ch.Consume(queue.Name, "", false, false, false, false, nil)
+	if err != nil {
+		log.Fatalf("%s: %s", "Could not register consumer", err)
+		return err
+	}
+
+	stopChan := make(chan bool)
+
+	go func() {
+		log.Printf("Consumer ready, PID: %d", os.Getpid())
+		for d := range msgCh {
+			handle(d.Body)
+
+			if err := d.Ack(false); err != nil {
+				log.Printf("Error acknowledging message : %v", err)
+			} else {
+				log.Printf("Acknowledged message")
+			}
+		}
+	}()
+
+	// Stop for program termination
+	<-stopChan
+
+	return nil
+}
+
+func (cliApp *CliApp) notificationService() service.NotificationService {
+	return service.NewNotificationService(
+		repository.NewNotificationRepository(),
+		repository.NewNotificationDeliveryRepository(),
+		repository.NewNotificationPreferenceRepository(),
+		service.NewMailService(),
+		cliApp.Config.Notification,
+	)
+}
+
+// relayNotifications queue the pending deliveries once their notification is committed, until the program terminates
+func (cliApp *CliApp) relayNotifications(notificationService service.NotificationService) {
+	interval := cliApp.Config.Notification.DispatchInterval
+	if interval <= 0 {
+		interval = time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		for cliApp.dispatchNotifications(notificationService) == dispatchLimit {
+		}
+	}
+}
+
+// dispatchNotifications queue a batch of the pending deliveries in one transaction, answers how many were queued
+func (cliApp *CliApp) dispatchNotifications(notificationService service.NotificationService) int {
+	ctx := context.Background()
+	dbConn, err := cliApp.DB.Acquire(ctx)
+	if err != nil {
+		log.Printf("Error Acquire DB: %v", err)
+		return 0
+	}
+	defer dbConn.Release()
+
+	tx, err := dbConn.Begin(ctx)
+	if err != nil {
+		log.Printf("Error Begin Transaction: %v", err)
+		return 0
+	}
+	defer tx.Rollback(ctx)
+
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, dbConn, tx)
+
+	count, err := notificationService.Dispatch(dbctx, cliApp.RabbitMQ, dispatchLimit)
+	if err != nil {
+		log.Printf("Error Dispatch Notification: %v", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		log.Printf("Error Commit Transaction: %v", err)
+		return 0
+	}
+
+	return count
+}
+
+// deliverNotification send the delivery, its result is kept in the delivery record
+func (cliApp *CliApp) deliverNotification(notificationService service.NotificationService, qData utils.NotificationData) {
+	ctx := context.Background()
+	dbConn, err := cliApp.DB.Acquire(ctx)
+	if err != nil {
+		log.Printf("Error Acquire DB: %v", err)
+		return
+	}
+	defer dbConn.Release()
+
+	var dbctx db.DBCtx
+	dbctx.Set(ctx, dbConn, nil)
+
+	err = notificationService.Deliver(dbctx, qData)
+	if err != nil {
+		log.Printf("Error Send Notification %d via %s: %v", qData.DeliveryID, qData.Channel, err)
+	}
+}
@@ -40,6 +40,27 @@ func New(c *config.Config) *CliApp {
 		log.Fatalln(err)
 	}
 
+	err = utils.SetupJWT(c.JWT, redis.RedisDefault)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	err = utils.SetupPasswordPolicy(c.Password)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	err = utils.SetupHasher(c.Hash)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	err = utils.SetupNotifier(c.Notifier)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	err = utils.SetupMail(c.Mail)
+	if err != nil {
+		log.Fatalln(err)
+	}
+
 	return &CliApp{
 		Config:    c,
 		DB:        db.Init(c),
@@ -52,8 +73,19 @@ func New(c *config.Config) *CliApp {
 
 func (cliApp *CliApp) Start(c *cli.Context) error {
 	switch queue := c.String("queue"); queue {
+	case "":
+	case utils.CMDQueueSendNotification:
+		return cliApp.QueueSendNotificationHandler(c)
 	case utils.CMDQueueSendMail:
 		return cliApp.QueueSendMailHandler(c)
+	case utils.CMDQueueSendPhone:
+		return cliApp.QueueSendPhoneHandler(c)
+	default:
+		return fmt.Errorf(`queue %s is not supported`, queue)
+	}
+
+	if len(c.String("client")) > 0 {
+		return cliApp.CreateApiClientHandler(c)
 	}
 
 	// Create channel for idle connections.
@@ -98,6 +130,26 @@ func (cliApp *CliApp) Flags() []cli.Flag {
 			Value: "",
 			Usage: "Run queue subsrciber in this server",
 		},
+		&cli.StringFlag{
+			Name:  "client",
+			Value: "",
+			Usage: "Create an api client for the channel (app or web) and print its credential",
+		},
+		&cli.StringFlag{
+			Name:  "client-name",
+			Value: "",
+			Usage: "Name of the created api client",
+		},
+		&cli.StringFlag{
+			Name:  "client-roles",
+			Value: "",
+			Usage: "Comma separated role slugs allowed for the created api client, empty allows admin for web and every role for app",
+		},
+		&cli.StringFlag{
+			Name:  "client-callback",
+			Value: "",
+			Usage: "Base url of the links sent to users of the created api client",
+		},
 	}
 
 	return flags
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"database/sql"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	API_CLIENT_PREFIX = "cli"
+)
+
+type ApiClient struct {
+	ID           int64          `db:"id"`
+	Code         string         `db:"code"`
+	Name         string         `db:"name"`
+	ClientID     string         `db:"client_id"`
+	SecretHash   string         `db:"secret_hash"`
+	Channel      string         `db:"channel"`
+	AllowedRoles []string       `db:"allowed_roles"`
+	CallbackURLs []string       `db:"callback_urls"`
+	Scopes       []string       `db:"scopes"`
+	Status       bool           `db:"status"`
+	CreatedDate  time.Time      `db:"created_date"`
+	CreatedBy    string         `db:"created_by"`
+	UpdatedDate  sql.NullTime   `db:"updated_date"`
+	UpdatedBy    sql.NullString `db:"updated_by"`
+	DeletedDate  sql.NullTime   `db:"deleted_date"`
+	DeletedBy    sql.NullString `db:"deleted_by"`
+	Version      int32          `db:"version"`
+}
+
+type ApiClientFilter struct {
+	Status  string
+	Channel string
+	Search  string
+	Paging  common.PaginateQueryOffset
+}
+
+// WhereGetAll build the filter conditions with $n placeholders, the args are passed along with the query
+func (f ApiClientFilter) WhereGetAll() (string, []interface{}) {
+	var q string
+	var args []interface{}
+
+	if len(f.Search) > 0 {
+		args = append(args, "%"+f.Search+"%")
+		n := len(args)
+
+		var orWhere []string
+		orWhere = append(orWhere, fmt.Sprintf(`lower(code) like lower($%d)`, n))
+		orWhere = append(orWhere, fmt.Sprintf(`lower(name) like lower($%d)`, n))
+		orWhere = append(orWhere, fmt.Sprintf(`lower(client_id) like lower($%d)`, n))
+
+		q += fmt.Sprintf(` and (%s) `, strings.Join(orWhere, ` or `))
+	}
+
+	if len(f.Channel) > 0 {
+		args = append(args, f.Channel)
+		q += fmt.Sprintf(` and channel = $%d `, len(args))
+	}
+
+	if len(f.Status) > 0 {
+		var status bool
+		if f.Status == "true" {
+			status = true
+		}
+		q += fmt.Sprintf(` and status = %v `, status)
+	}
+
+	return q, args
+}
+
+// CheckAllowedRole check the client may act for the role, no allowed roles means the default roles of its channel
+func (c ApiClient) CheckAllowedRole(role string) error {
+	allowedRoles := c.AllowedRoles
+	if len(allowedRoles) <= 0 {
+		allowedRoles = DefaultAllowedRoles(c.Channel)
+	}
+	if len(allowedRoles) <= 0 {
+		return nil
+	}
+
+	arrStr := new(common.ArrStr)
+	if allowed, _ := arrStr.InArray(role, allowedRoles); !allowed {
+		return fmt.Errorf("%s", `client role is invalid`)
+	}
+
+	return nil
+}
+
+// CheckCallbackURL check the url is registered for the client
+func (c ApiClient) CheckCallbackURL(callbackURL string) error {
+	arrStr := new(common.ArrStr)
+	if registered, _ := arrStr.InArray(callbackURL, c.CallbackURLs); !registered {
+		return fmt.Errorf("%s", `callback url is not registered`)
+	}
+
+	return nil
+}
+
+// CallbackURL base url of the links sent to users of the client
+func (c ApiClient) CallbackURL() string {
+	if len(c.CallbackURLs) <= 0 {
+		return ""
+	}
+
+	return strings.TrimRight(c.CallbackURLs[0], "/")
+}
+
+// DefaultAllowedRoles roles of a client created without any, web is for admins only and app allows every role
+func DefaultAllowedRoles(channel string) []string {
+	if channel == utils.ChannelWeb {
+		return []string{ROLE_ADMIN}
+	}
+
+	return []string{}
+}
+
+func CheckValidClientChannel(channel string) error {
+	if channel != utils.ChannelApp && channel != utils.ChannelWeb {
+		return fmt.Errorf("%s", `channel is invalid`)
+	}
+
+	return nil
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	AUDIT_EVENT_LOCKOUT = "lockout"
+	AUDIT_EVENT_UNLOCK  = "unlock"
+)
+
+type AuthAudit struct {
+	ID          int64          `db:"id"`
+	Event       string         `db:"event"`
+	Identifier  string         `db:"identifier"`
+	IP          string         `db:"ip"`
+	Detail      sql.NullString `db:"detail"`
+	CreatedBy   sql.NullString `db:"created_by"`
+	CreatedDate time.Time      `db:"created_date"`
+}
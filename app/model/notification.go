@@ -0,0 +1,123 @@
+package model
+
+import (
+	"database/sql"
+	"fiber-starter/pkg/utils"
+	"time"
+)
+
+const (
+	NOTIFY_CHANNEL_EMAIL   = "email"
+	NOTIFY_CHANNEL_SMS     = "sms"
+	NOTIFY_CHANNEL_INAPP   = "inapp"
+	NOTIFY_CHANNEL_WEBHOOK = "webhook"
+
+	DELIVERY_PENDING = "pending"
+	DELIVERY_QUEUED  = "queued"
+	DELIVERY_SENT    = "sent"
+	DELIVERY_FAILED  = "failed"
+
+	NOTIFICATION_PAGE_LIMIT = 20
+)
+
+type Notification struct {
+	ID          int64        `db:"id"`
+	UserID      int64        `db:"user_id"`
+	Type        string       `db:"type"`
+	Title       string       `db:"title"`
+	Body        string       `db:"body"`
+	Inbox       bool         `db:"inbox"`
+	ReadDate    sql.NullTime `db:"read_date"`
+	CreatedDate time.Time    `db:"created_date"`
+}
+
+type NotificationDelivery struct {
+	ID             int64          `db:"id"`
+	NotificationID int64          `db:"notification_id"`
+	Channel        string         `db:"channel"`
+	Recipient      string         `db:"recipient"`
+	Status         string         `db:"status"`
+	Error          sql.NullString `db:"error"`
+	CreatedDate    time.Time      `db:"created_date"`
+	SentDate       sql.NullTime   `db:"sent_date"`
+	// Payload queued once the delivery is committed
+	Payload []byte `db:"payload"`
+}
+
+type NotificationPreference struct {
+	UserID      int64     `db:"user_id"`
+	Type        string    `db:"type"`
+	Channel     string    `db:"channel"`
+	Enabled     bool      `db:"enabled"`
+	UpdatedDate time.Time `db:"updated_date"`
+}
+
+// NotificationType what a notification says and the channels it may go to
+type NotificationType struct {
+	Title string
+	// Message text template of the sms, inbox and webhook channels, email uses the template of the type
+	Message  string
+	Channels []string
+	// Defaults channels enabled for users without a preference
+	Defaults []string
+	// Security notifications carry a token for the address they are sent to, they ignore the
+	// preferences, are not kept in the inbox and their message is not stored
+	Security bool
+}
+
+var NotificationTypes = map[string]NotificationType{
+	utils.MAIL_FOR_USERACTIVATION: {
+		Title:    "User Activation",
+		Message:  "[sample] Your activation code is {{.TokenURL}}, valid for {{.ExpiredTime}} minutes.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_SMS},
+		Security: true,
+	},
+	utils.MAIL_FOR_RESETPASS: {
+		Title:    "Reset Password",
+		Message:  "[sample] Your reset password code is {{.TokenURL}}, valid for {{.ExpiredTime}} minutes.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_SMS},
+		Security: true,
+	},
+	utils.MAIL_FOR_LOGIN: {
+		Title:    "Login",
+		Message:  "[sample] Your login code is {{.TokenURL}}, valid for {{.ExpiredTime}} minutes.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_SMS},
+		Security: true,
+	},
+	utils.MAIL_FOR_INVITE: {
+		Title:    "Invitation",
+		Message:  "[sample] Set your password at {{.TokenURL}}, valid for {{.ExpiredTime}} hours.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL},
+		Security: true,
+	},
+	utils.MAIL_FOR_CHANGEEMAIL: {
+		Title:    "Change Email",
+		Message:  "[sample] Your change email code is {{.TokenURL}}, valid for {{.ExpiredTime}} minutes.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL},
+		Security: true,
+	},
+	utils.MAIL_FOR_CHANGEPHONE: {
+		Title:    "Change Phone",
+		Message:  "[sample] Your change phone code is {{.TokenURL}}, valid for {{.ExpiredTime}} minutes.",
+		Channels: []string{NOTIFY_CHANNEL_SMS},
+		Security: true,
+	},
+	utils.MAIL_FOR_PASSWORDCHANGE: {
+		Title:    "Password Changed",
+		Message:  "Your password was changed on {{.Date}}. If it was not you, reset your password now.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_SMS, NOTIFY_CHANNEL_INAPP, NOTIFY_CHANNEL_WEBHOOK},
+		Defaults: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_INAPP},
+	},
+	utils.MAIL_FOR_EMAILCHANGE: {
+		Title:    "Email Changed",
+		Message:  "Your email was changed to {{.Detail}} on {{.Date}}. If it was not you, contact us now.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_SMS, NOTIFY_CHANNEL_INAPP, NOTIFY_CHANNEL_WEBHOOK},
+		Defaults: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_INAPP},
+	},
+	utils.MAIL_FOR_PHONECHANGE: {
+		Title:    "Phone Changed",
+		Message:  "Your phone was changed to {{.Detail}} on {{.Date}}. If it was not you, contact us now.",
+		Channels: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_SMS, NOTIFY_CHANNEL_INAPP, NOTIFY_CHANNEL_WEBHOOK},
+		Defaults: []string{NOTIFY_CHANNEL_EMAIL, NOTIFY_CHANNEL_INAPP},
+	},
+}
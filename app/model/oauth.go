@@ -0,0 +1,47 @@
+package model
+
+const (
+	OAUTH_CODE_EXPIRED_TIME = 1 // In minutes
+	OAUTH_FAMILY_PREFIX     = "oauth"
+
+	OAUTH_GRANT_AUTHORIZATION_CODE = "authorization_code"
+	OAUTH_GRANT_CLIENT_CREDENTIALS = "client_credentials"
+	OAUTH_GRANT_REFRESH_TOKEN      = "refresh_token"
+
+	OAUTH_TOKEN_HINT_ACCESS  = "access_token"
+	OAUTH_TOKEN_HINT_REFRESH = "refresh_token"
+)
+
+// OAuthCode authorization code granted by a user to a client, kept until exchanged
+type OAuthCode struct {
+	ClientID            string   `json:"client_id"`
+	UserID              int64    `json:"user_id"`
+	RedirectURI         string   `json:"redirect_uri"`
+	Scopes              []string `json:"scopes"`
+	CodeChallenge       string   `json:"code_challenge"`
+	CodeChallengeMethod string   `json:"code_challenge_method"`
+}
+
+// OAuthError error answered by the oauth endpoints, see RFC 6749 section 5.2
+type OAuthError struct {
+	Code        string
+	Description string
+	Err         error
+}
+
+func (e *OAuthError) Error() string {
+	return e.Description
+}
+
+func (e *OAuthError) Unwrap() error {
+	return e.Err
+}
+
+func NewOAuthError(code, description string) *OAuthError {
+	return &OAuthError{Code: code, Description: description}
+}
+
+// WrapOAuthError answer err as the oauth error code
+func WrapOAuthError(code string, err error) *OAuthError {
+	return &OAuthError{Code: code, Description: err.Error(), Err: err}
+}
@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type PasswordHistory struct {
+	ID           int64     `db:"id"`
+	UserID       int64     `db:"user_id"`
+	PasswordHash string    `db:"password_hash"`
+	CreatedDate  time.Time `db:"created_date"`
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+const (
+	PERMISSION_ROLE_CREATE   = "role:create"
+	PERMISSION_ROLE_READ     = "role:read"
+	PERMISSION_ROLE_UPDATE   = "role:update"
+	PERMISSION_ROLE_DELETE   = "role:delete"
+	PERMISSION_USER_CREATE   = "user:create"
+	PERMISSION_USER_READ     = "user:read"
+	PERMISSION_USER_UPDATE   = "user:update"
+	PERMISSION_USER_DELETE   = "user:delete"
+	PERMISSION_USER_LOGOUT   = "user:logout"
+	PERMISSION_USER_UNLOCK   = "user:unlock"
+	PERMISSION_CLIENT_CREATE = "client:create"
+	PERMISSION_CLIENT_READ   = "client:read"
+	PERMISSION_CLIENT_UPDATE = "client:update"
+	PERMISSION_CLIENT_DELETE = "client:delete"
+)
+
+type Permission struct {
+	ID          int64     `db:"id"`
+	Slug        string    `db:"slug"`
+	CreatedDate time.Time `db:"created_date"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	REFRESH_FAMILY_PREFIX = "family"
+)
+
+type RefreshToken struct {
+	ID          int64          `db:"id"`
+	UserID      int64          `db:"user_id"`
+	TokenHash   string         `db:"token_hash"`
+	Family      string         `db:"family"`
+	ClientID    sql.NullString `db:"client_id"`
+	Scopes      []string       `db:"scopes"`
+	ExpiredDate time.Time      `db:"expired_date"`
+	RevokedDate sql.NullTime   `db:"revoked_date"`
+	CreatedDate time.Time      `db:"created_date"`
+}
+
+// AuthToken pair of access token and refresh token issued to a user, a pending mfa token
+// when a second factor is still required or a password change token when the password must be set
+type AuthToken struct {
+	AccessToken         string
+	AccessExpires       int64
+	RefreshToken        string
+	RefreshExpires      int64
+	MFAToken            string
+	PasswordChangeToken string
+}
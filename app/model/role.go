@@ -3,7 +3,6 @@ package model
 import (
 	"database/sql"
 	"fiber-starter/pkg/common"
-	"fiber-starter/pkg/utils"
 	"fmt"
 	"strings"
 	"time"
@@ -15,8 +14,6 @@ const (
 	ROLE_PREFIX = "role"
 )
 
-var roleSlugList = []string{ROLE_ADMIN, ROLE_CUST}
-
 type Role struct {
 	ID          int64          `db:"id"`
 	Code        string         `db:"code"`
@@ -60,22 +57,3 @@ func (f RoleFilter) WhereGetAll() string {
 
 	return q
 }
-
-func CheckValidChannelRole(channel string, role string) error {
-	if channel == utils.ChannelWeb && role != ROLE_ADMIN {
-		return fmt.Errorf("%s", `channel role is invalid`)
-	}
-
-	return nil
-}
-
-func CheckValidRoleSlug(slug string) error {
-	var err error
-	if !common.CheckStringContains(slug, roleSlugList) {
-		statusStr := strings.Join(roleSlugList, ", ")
-		errMsg := fmt.Sprintf(`invalid role arguments in action not includes in %s.`, statusStr)
-		err = fmt.Errorf("%s", errMsg)
-	}
-
-	return err
-}
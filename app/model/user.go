@@ -9,30 +9,30 @@ import (
 )
 
 const (
-	USER_PREFIX  = "user"
-	DEFAULT_PASS = "12345678"
+	USER_PREFIX = "user"
 )
 
 type User struct {
-	ID            int64          `db:"id"`
-	Code          string         `db:"code"`
-	RoleID        int32          `db:"role_id"`
-	Role          string         `db:"role"`
-	Name          string         `db:"name"`
-	Email         string         `db:"email"`
-	Phone         string         `db:"phone"`
-	Password      string         `db:"password"`
-	Address       sql.NullString `db:"address"`
-	Img           sql.NullString `db:"img"`
-	RememberToken sql.NullString `db:"remember_token"`
-	Status        bool           `db:"status"`
-	CreatedDate   time.Time      `db:"created_date"`
-	CreatedBy     string         `db:"created_by"`
-	UpdatedDate   sql.NullTime   `db:"updated_date"`
-	UpdatedBy     sql.NullString `db:"updated_by"`
-	DeletedDate   sql.NullTime   `db:"deleted_date"`
-	DeletedBy     sql.NullString `db:"deleted_by"`
-	Version       int32          `db:"version"`
+	ID                 int64          `db:"id"`
+	Code               string         `db:"code"`
+	RoleID             int32          `db:"role_id"`
+	Role               string         `db:"role"`
+	Name               string         `db:"name"`
+	Email              string         `db:"email"`
+	Phone              string         `db:"phone"`
+	Password           string         `db:"password"`
+	Address            sql.NullString `db:"address"`
+	Img                sql.NullString `db:"img"`
+	RememberToken      sql.NullString `db:"remember_token"`
+	Status             bool           `db:"status"`
+	MustChangePassword bool           `db:"must_change_password"`
+	CreatedDate        time.Time      `db:"created_date"`
+	CreatedBy          string         `db:"created_by"`
+	UpdatedDate        sql.NullTime   `db:"updated_date"`
+	UpdatedBy          sql.NullString `db:"updated_by"`
+	DeletedDate        sql.NullTime   `db:"deleted_date"`
+	DeletedBy          sql.NullString `db:"deleted_by"`
+	Version            int32          `db:"version"`
 }
 
 type UserFilter struct {
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	OIDC_STATE_EXPIRED_TIME  = 10 // In minutes
+	OIDC_SIGNUP_EXPIRED_TIME = 10 // In minutes
+)
+
+type UserIdentity struct {
+	ID            int64          `db:"id"`
+	UserID        int64          `db:"user_id"`
+	Provider      string         `db:"provider"`
+	Subject       string         `db:"subject"`
+	Email         sql.NullString `db:"email"`
+	CreatedDate   time.Time      `db:"created_date"`
+	LastLoginDate sql.NullTime   `db:"last_login_date"`
+}
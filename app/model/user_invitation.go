@@ -0,0 +1,22 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	INVITATION_EXPIRED_TIME            = 72 // In hours
+	PASSWORD_CHANGE_TOKEN_EXPIRED_TIME = 10 // In minutes
+)
+
+type UserInvitation struct {
+	ID           int64        `db:"id"`
+	UserID       int64        `db:"user_id"`
+	TokenHash    string       `db:"token_hash"`
+	ExpiredDate  time.Time    `db:"expired_date"`
+	AcceptedDate sql.NullTime `db:"accepted_date"`
+	RevokedDate  sql.NullTime `db:"revoked_date"`
+	CreatedDate  time.Time    `db:"created_date"`
+	CreatedBy    string       `db:"created_by"`
+}
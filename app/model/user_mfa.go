@@ -0,0 +1,23 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	MFA_TOKEN_EXPIRED_TIME = 5 // In minutes
+	MFA_TOKEN_MAX_ATTEMPTS = 5 // Pending mfa token is revoked after it
+	MFA_RECOVERY_CODES     = 10
+)
+
+type UserMFA struct {
+	ID            int64        `db:"id"`
+	UserID        int64        `db:"user_id"`
+	Secret        string       `db:"secret"`
+	RecoveryCodes []string     `db:"recovery_codes"`
+	LastUsedStep  int64        `db:"last_used_step"`
+	Status        bool         `db:"status"`
+	CreatedDate   time.Time    `db:"created_date"`
+	UpdatedDate   sql.NullTime `db:"updated_date"`
+}
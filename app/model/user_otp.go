@@ -1,34 +1,65 @@
 package model
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/hex"
 	"fiber-starter/pkg/common"
 	"fiber-starter/pkg/utils"
-	"math/rand"
+	"fmt"
+	"math/big"
 	"time"
 )
 
 const (
-	TOKEN_EXPIRED_TIME = 3 // In minutes
-	OTP_VIA_EMAIL      = "email"
-	OTP_VIA_PHONE      = "phone"
+	TOKEN_EXPIRED_TIME        = 3  // In minutes
+	VERIFY_TOKEN_EXPIRED_TIME = 15 // In minutes
+	OTP_MAX_ATTEMPTS          = 5
+	OTP_VIA_EMAIL             = "email"
+	OTP_VIA_PHONE             = "phone"
 )
 
 type UserOTP struct {
-	ID          int64        `db:"id"`
-	Email       string       `db:"email"`
-	Phone       string       `db:"phone"`
-	OTP         string       `db:"otp"`
-	ExpiredDate time.Time    `db:"expired_date"`
-	CreatedDate time.Time    `db:"created_date"`
-	UpdatedDate sql.NullTime `db:"updated_date"`
+	ID           int64        `db:"id"`
+	Email        string       `db:"email"`
+	Phone        string       `db:"phone"`
+	Purpose      string       `db:"purpose"`
+	OTPHash      string       `db:"otp_hash"`
+	Salt         string       `db:"salt"`
+	AttemptsLeft int16        `db:"attempts_left"`
+	ExpiredDate  time.Time    `db:"expired_date"`
+	VerifiedDate sql.NullTime `db:"verified_date"`
+	UsedDate     sql.NullTime `db:"used_date"`
+	CreatedDate  time.Time    `db:"created_date"`
 }
 
-func (u UserOTP) GenerateOTP() (string, error) {
-	rand.Seed(time.Now().UnixNano())
-	otp, err := common.GenerateString(`[\d]{6}`)
+// GenerateOTP create a 6 digit code from a cryptographically secure source
+func GenerateOTP() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// SetOTP store the salted hash of the otp, the otp itself is never stored
+func (u *UserOTP) SetOTP(otp string) error {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+	u.Salt = hex.EncodeToString(salt)
+	u.OTPHash = hashOTP(otp, u.Salt)
 
-	return otp, err
+	return nil
+}
+
+// CheckOTP compare the otp with the stored hash
+func (u UserOTP) CheckOTP(otp string) bool {
+	return subtle.ConstantTimeCompare([]byte(u.OTPHash), []byte(hashOTP(otp, u.Salt))) == 1
 }
 
 func (u UserOTP) GetExpiredDate() time.Time {
@@ -36,6 +67,11 @@ func (u UserOTP) GetExpiredDate() time.Time {
 	return now.Add(time.Minute * TOKEN_EXPIRED_TIME)
 }
 
+func hashOTP(otp, salt string) string {
+	sum := sha256.Sum256([]byte(salt + otp))
+	return hex.EncodeToString(sum[:])
+}
+
 func ViaValidMailPhoneChannel(channel, emailPhone string) (string, bool) {
 	var via string
 	if common.IsEmail(emailPhone) {
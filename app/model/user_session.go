@@ -0,0 +1,30 @@
+package model
+
+import (
+	"database/sql"
+	"time"
+)
+
+const (
+	SESSION_TOUCH_INTERVAL = 60 // In seconds
+)
+
+// UserSession login of a user on a device, bound to its refresh token family
+type UserSession struct {
+	ID           int64        `db:"id"`
+	UserID       int64        `db:"user_id"`
+	Family       string       `db:"family"`
+	DeviceName   string       `db:"device_name"`
+	UserAgent    string       `db:"user_agent"`
+	IP           string       `db:"ip"`
+	CreatedDate  time.Time    `db:"created_date"`
+	LastSeenDate time.Time    `db:"last_seen_date"`
+	RevokedDate  sql.NullTime `db:"revoked_date"`
+}
+
+// Device where a user logs in from
+type Device struct {
+	Name      string
+	UserAgent string
+	IP        string
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fmt"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type ApiClientRepository interface {
+	Insert(dbctx db.DBCtx, cl model.ApiClient) (model.ApiClient, error)
+	Update(dbctx db.DBCtx, cl model.ApiClient) error
+	UpdateSecret(dbctx db.DBCtx, cl model.ApiClient) error
+	Delete(dbctx db.DBCtx, code, deletedBy string) error
+	GetAll(dbctx db.DBCtx, f model.ApiClientFilter) ([]model.ApiClient, error)
+	GetAllTotal(dbctx db.DBCtx, f model.ApiClientFilter) (int64, error)
+	GetByCode(dbctx db.DBCtx, code string) (model.ApiClient, error)
+	GetByClientID(dbctx db.DBCtx, clientID string) (model.ApiClient, error)
+	GetVersionByCode(dbctx db.DBCtx, code string) (int32, error)
+}
+
+type apiClientRepository struct {
+}
+
+func NewApiClientRepository() *apiClientRepository {
+	return &apiClientRepository{}
+}
+
+func (r *apiClientRepository) Insert(dbctx db.DBCtx, cl model.ApiClient) (model.ApiClient, error) {
+	var ID int64
+
+	cl.Version = 1
+	cl.CreatedDate = time.Now().In(time.UTC)
+
+	paramQ := []interface{}{cl.Code, cl.Name, cl.ClientID, cl.SecretHash, cl.Channel, cl.AllowedRoles, cl.CallbackURLs, cl.Scopes, cl.Status, cl.CreatedDate, cl.CreatedBy, cl.Version}
+	q := `insert into api_clients (code, name, client_id, secret_hash, channel, allowed_roles, callback_urls, scopes, status, created_date, created_by, version) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) returning id`
+
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
+	cl.ID = ID
+
+	return cl, err
+}
+
+func (r *apiClientRepository) Update(dbctx db.DBCtx, cl model.ApiClient) error {
+	paramQ := []interface{}{cl.Name, cl.Channel, cl.AllowedRoles, cl.CallbackURLs, cl.Scopes, cl.Status, cl.UpdatedDate.Time, cl.UpdatedBy.String, cl.Version, cl.Code}
+	q := `update api_clients set name = $1, channel = $2, allowed_roles = $3, callback_urls = $4, scopes = $5, status = $6, updated_date = $7, updated_by = $8, version = $9 where deleted_date is null and code = $10`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, paramQ...)
+
+	return err
+}
+
+func (r *apiClientRepository) UpdateSecret(dbctx db.DBCtx, cl model.ApiClient) error {
+	paramQ := []interface{}{cl.SecretHash, cl.UpdatedDate.Time, cl.UpdatedBy.String, cl.Version, cl.Code}
+	q := `update api_clients set secret_hash = $1, updated_date = $2, updated_by = $3, version = $4 where deleted_date is null and code = $5`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, paramQ...)
+
+	return err
+}
+
+func (r *apiClientRepository) Delete(dbctx db.DBCtx, code, deletedBy string) error {
+	timeStamp := time.Now().In(time.UTC)
+	_, err := dbctx.TX.Exec(dbctx.Ctx,
+		"update api_clients set updated_date = $1, updated_by = $2, deleted_date = $3, deleted_by = $4, status = $5 where code = $6",
+		timeStamp, deletedBy, timeStamp, deletedBy, false, code,
+	)
+
+	return err
+}
+
+func (r *apiClientRepository) GetAll(dbctx db.DBCtx, f model.ApiClientFilter) ([]model.ApiClient, error) {
+	var clients []model.ApiClient
+
+	where, args := f.WhereGetAll()
+	q := `select * from api_clients where deleted_date is null`
+	q += where
+	q += fmt.Sprintf(` %s`, common.OrderByOffsetLimitSQL(f.Paging))
+
+	err := pgxscan.Select(dbctx.Ctx, dbctx.DB, &clients, q, args...)
+
+	return clients, err
+}
+
+func (r *apiClientRepository) GetAllTotal(dbctx db.DBCtx, f model.ApiClientFilter) (int64, error) {
+	var total int64
+
+	where, args := f.WhereGetAll()
+	q := `select count(*) from api_clients where deleted_date is null`
+	q += where
+
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &total, q, args...)
+
+	return total, err
+}
+
+func (r *apiClientRepository) GetByCode(dbctx db.DBCtx, code string) (model.ApiClient, error) {
+	var cl model.ApiClient
+
+	q := `select * from api_clients where deleted_date is null and code = $1 limit 1`
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &cl, q, code)
+
+	return cl, err
+}
+
+func (r *apiClientRepository) GetByClientID(dbctx db.DBCtx, clientID string) (model.ApiClient, error) {
+	var cl model.ApiClient
+
+	q := fmt.Sprintf(`select * from api_clients where deleted_date is null and status = %v and client_id = $1 limit 1`, true)
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &cl, q, clientID)
+
+	return cl, err
+}
+
+func (r *apiClientRepository) GetVersionByCode(dbctx db.DBCtx, code string) (int32, error) {
+	var v int32
+
+	q := `select version from api_clients where deleted_date is null and code = $1 limit 1`
+	err := dbctx.DB.QueryRow(dbctx.Ctx, q, code).Scan(&v)
+
+	return v, err
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"time"
+)
+
+type AuthAuditRepository interface {
+	Insert(dbctx db.DBCtx, a model.AuthAudit) error
+}
+
+type authAuditRepository struct {
+}
+
+func NewAuthAuditRepository() *authAuditRepository {
+	return &authAuditRepository{}
+}
+
+// Insert write audit outside the request transaction, so it is kept when the request is rolled back
+func (r *authAuditRepository) Insert(dbctx db.DBCtx, a model.AuthAudit) error {
+	a.CreatedDate = time.Now().In(time.UTC)
+
+	paramQ := []interface{}{a.Event, a.Identifier, a.IP, a.Detail, a.CreatedBy, a.CreatedDate}
+	q := `insert into auth_audits (event, identifier, ip, detail, created_by, created_date) values ($1, $2, $3, $4, $5, $6)`
+	_, err := dbctx.DB.Exec(dbctx.Ctx, q, paramQ...)
+
+	return err
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"database/sql"
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type NotificationDeliveryRepository interface {
+	Insert(dbctx db.DBCtx, d model.NotificationDelivery) (model.NotificationDelivery, error)
+	GetPending(dbctx db.DBCtx, limit int) ([]model.NotificationDelivery, error)
+	MarkQueued(dbctx db.DBCtx, id int64) error
+	UpdateStatus(dbctx db.DBCtx, id int64, status string, deliveryErr error) error
+}
+
+type notificationDeliveryRepository struct {
+}
+
+func NewNotificationDeliveryRepository() *notificationDeliveryRepository {
+	return &notificationDeliveryRepository{}
+}
+
+func (r *notificationDeliveryRepository) Insert(dbctx db.DBCtx, d model.NotificationDelivery) (model.NotificationDelivery, error) {
+	var ID int64
+	d.CreatedDate = time.Now().In(time.UTC)
+
+	paramQ := []interface{}{d.NotificationID, d.Channel, d.Recipient, d.Status, d.CreatedDate, string(d.Payload)}
+	q := `insert into notification_deliveries (notification_id, channel, recipient, status, created_date, payload) values ($1, $2, $3, $4, $5, $6) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
+	d.ID = ID
+
+	return d, err
+}
+
+// GetPending get the committed deliveries not queued yet and lock them, other dispatchers skip them
+func (r *notificationDeliveryRepository) GetPending(dbctx db.DBCtx, limit int) ([]model.NotificationDelivery, error) {
+	var list []model.NotificationDelivery
+
+	q := `select id, notification_id, channel, recipient, status, error, created_date, sent_date, payload from notification_deliveries where status = $1 and payload is not null order by id limit $2 for update skip locked`
+	err := pgxscan.Select(dbctx.Ctx, dbctx.TX, &list, q, model.DELIVERY_PENDING, limit)
+
+	return list, err
+}
+
+// MarkQueued the payload is dropped once queued, it may carry an otp
+func (r *notificationDeliveryRepository) MarkQueued(dbctx db.DBCtx, id int64) error {
+	q := `update notification_deliveries set status = $1, payload = null where status = $2 and id = $3`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, model.DELIVERY_QUEUED, model.DELIVERY_PENDING, id)
+
+	return err
+}
+
+// UpdateStatus write outside transaction, it is called by the queue consumer
+func (r *notificationDeliveryRepository) UpdateStatus(dbctx db.DBCtx, id int64, status string, deliveryErr error) error {
+	var errMsg sql.NullString
+	var sentDate sql.NullTime
+	if deliveryErr != nil {
+		errMsg = sql.NullString{Valid: true, String: deliveryErr.Error()}
+	} else {
+		sentDate = sql.NullTime{Valid: true, Time: time.Now().In(time.UTC)}
+	}
+
+	q := `update notification_deliveries set status = $1, error = $2, sent_date = $3 where id = $4`
+	_, err := dbctx.DB.Exec(dbctx.Ctx, q, status, errMsg, sentDate, id)
+
+	return err
+}
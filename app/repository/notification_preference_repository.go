@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type NotificationPreferenceRepository interface {
+	Upsert(dbctx db.DBCtx, p model.NotificationPreference) error
+	GetByUserID(dbctx db.DBCtx, userID int64) ([]model.NotificationPreference, error)
+}
+
+type notificationPreferenceRepository struct {
+}
+
+func NewNotificationPreferenceRepository() *notificationPreferenceRepository {
+	return &notificationPreferenceRepository{}
+}
+
+func (r *notificationPreferenceRepository) Upsert(dbctx db.DBCtx, p model.NotificationPreference) error {
+	paramQ := []interface{}{p.UserID, p.Type, p.Channel, p.Enabled, time.Now().In(time.UTC)}
+	q := `insert into notification_preferences (user_id, type, channel, enabled, updated_date) values ($1, $2, $3, $4, $5)
+		on conflict (user_id, type, channel) do update set enabled = excluded.enabled, updated_date = excluded.updated_date`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, paramQ...)
+
+	return err
+}
+
+func (r *notificationPreferenceRepository) GetByUserID(dbctx db.DBCtx, userID int64) ([]model.NotificationPreference, error) {
+	var preferences []model.NotificationPreference
+
+	q := `select * from notification_preferences where user_id = $1`
+	err := pgxscan.Select(dbctx.Ctx, dbctx.DB, &preferences, q, userID)
+
+	return preferences, err
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fmt"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type NotificationRepository interface {
+	Insert(dbctx db.DBCtx, n model.Notification) (model.Notification, error)
+	MarkRead(dbctx db.DBCtx, id, userID int64) (int64, error)
+	MarkAllRead(dbctx db.DBCtx, userID int64) error
+	GetInbox(dbctx db.DBCtx, userID int64, unread bool, pg common.PaginateQueryOffset) ([]model.Notification, error)
+	GetInboxTotal(dbctx db.DBCtx, userID int64, unread bool) (int64, error)
+}
+
+type notificationRepository struct {
+}
+
+func NewNotificationRepository() *notificationRepository {
+	return &notificationRepository{}
+}
+
+func (r *notificationRepository) Insert(dbctx db.DBCtx, n model.Notification) (model.Notification, error) {
+	var ID int64
+	n.CreatedDate = time.Now().In(time.UTC)
+
+	paramQ := []interface{}{n.UserID, n.Type, n.Title, n.Body, n.Inbox, n.CreatedDate}
+	q := `insert into notifications (user_id, type, title, body, inbox, created_date) values ($1, $2, $3, $4, $5, $6) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
+	n.ID = ID
+
+	return n, err
+}
+
+// MarkRead returns the number of inbox notifications of the user with the id
+func (r *notificationRepository) MarkRead(dbctx db.DBCtx, id, userID int64) (int64, error) {
+	q := `update notifications set read_date = coalesce(read_date, $1) where id = $2 and user_id = $3 and inbox`
+	exec, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), id, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return exec.RowsAffected(), nil
+}
+
+func (r *notificationRepository) MarkAllRead(dbctx db.DBCtx, userID int64) error {
+	q := `update notifications set read_date = $1 where user_id = $2 and inbox and read_date is null`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), userID)
+
+	return err
+}
+
+func (r *notificationRepository) GetInbox(dbctx db.DBCtx, userID int64, unread bool, pg common.PaginateQueryOffset) ([]model.Notification, error) {
+	var notifications []model.Notification
+
+	q := `select * from notifications where user_id = $1 and inbox`
+	if unread {
+		q += ` and read_date is null`
+	}
+	q += fmt.Sprintf(` %s`, common.OrderByOffsetLimitSQL(pg))
+	err := pgxscan.Select(dbctx.Ctx, dbctx.DB, &notifications, q, userID)
+
+	return notifications, err
+}
+
+func (r *notificationRepository) GetInboxTotal(dbctx db.DBCtx, userID int64, unread bool) (int64, error) {
+	var total int64
+
+	q := `select count(*) from notifications where user_id = $1 and inbox`
+	if unread {
+		q += ` and read_date is null`
+	}
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &total, q, userID)
+
+	return total, err
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type PasswordHistoryRepository interface {
+	Insert(dbctx db.DBCtx, h model.PasswordHistory) (model.PasswordHistory, error)
+	GetLatestByUserID(dbctx db.DBCtx, userID int64, limit int) ([]model.PasswordHistory, error)
+	DeleteOlderByUserID(dbctx db.DBCtx, userID int64, keep int) error
+}
+
+type passwordHistoryRepository struct {
+}
+
+func NewPasswordHistoryRepository() *passwordHistoryRepository {
+	return &passwordHistoryRepository{}
+}
+
+func (r *passwordHistoryRepository) Insert(dbctx db.DBCtx, h model.PasswordHistory) (model.PasswordHistory, error) {
+	var ID int64
+	h.CreatedDate = time.Now().In(time.UTC)
+
+	q := `insert into password_histories (user_id, password_hash, created_date) values ($1, $2, $3) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, h.UserID, h.PasswordHash, h.CreatedDate).Scan(&ID)
+	h.ID = ID
+
+	return h, err
+}
+
+func (r *passwordHistoryRepository) GetLatestByUserID(dbctx db.DBCtx, userID int64, limit int) ([]model.PasswordHistory, error) {
+	var histories []model.PasswordHistory
+
+	q := `select * from password_histories where user_id = $1 order by id desc limit $2`
+	err := pgxscan.Select(dbctx.Ctx, dbctx.DB, &histories, q, userID, limit)
+
+	return histories, err
+}
+
+func (r *passwordHistoryRepository) DeleteOlderByUserID(dbctx db.DBCtx, userID int64, keep int) error {
+	q := `delete from password_histories where user_id = $1 and id not in (select id from password_histories where user_id = $1 order by id desc limit $2)`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, userID, keep)
+
+	return err
+}
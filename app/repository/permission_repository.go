@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fiber-starter/db"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type PermissionRepository interface {
+	GrantToRole(dbctx db.DBCtx, roleID int64, slugs []string, createdBy string) error
+	RevokeFromRole(dbctx db.DBCtx, roleID int64, slugs []string) error
+	GetSlugsByRoleSlug(dbctx db.DBCtx, roleSlug string) ([]string, error)
+	GetSlugsByRoleID(dbctx db.DBCtx, roleID int64) ([]string, error)
+}
+
+type permissionRepository struct {
+}
+
+func NewPermissionRepository() *permissionRepository {
+	return &permissionRepository{}
+}
+
+func (r *permissionRepository) GrantToRole(dbctx db.DBCtx, roleID int64, slugs []string, createdBy string) error {
+	timeStamp := time.Now().In(time.UTC)
+
+	// Register permissions not yet stored
+	q := `insert into permissions (slug, created_date) select unnest($1::varchar[]), $2 on conflict (slug) do nothing`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, slugs, timeStamp)
+	if err != nil {
+		return err
+	}
+
+	q = `insert into role_permissions (role_id, permission_id, created_date, created_by)
+		select $1, id, $2, $3 from permissions where slug = any($4) on conflict do nothing`
+	_, err = dbctx.TX.Exec(dbctx.Ctx, q, roleID, timeStamp, createdBy, slugs)
+
+	return err
+}
+
+func (r *permissionRepository) RevokeFromRole(dbctx db.DBCtx, roleID int64, slugs []string) error {
+	q := `delete from role_permissions where role_id = $1 and permission_id in (select id from permissions where slug = any($2))`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, roleID, slugs)
+
+	return err
+}
+
+func (r *permissionRepository) GetSlugsByRoleSlug(dbctx db.DBCtx, roleSlug string) ([]string, error) {
+	var slugs []string
+
+	q := `select p.slug from permissions p
+		join role_permissions rp on rp.permission_id = p.id
+		join roles r on r.id = rp.role_id
+		where r.deleted_date is null and r.status = true and r.slug = $1 order by p.slug`
+	err := pgxscan.Select(dbctx.Ctx, dbctx.DB, &slugs, q, roleSlug)
+
+	return slugs, err
+}
+
+func (r *permissionRepository) GetSlugsByRoleID(dbctx db.DBCtx, roleID int64) ([]string, error) {
+	var slugs []string
+
+	q := `select p.slug from permissions p join role_permissions rp on rp.permission_id = p.id where rp.role_id = $1 order by p.slug`
+	err := pgxscan.Select(dbctx.Ctx, dbctx.DB, &slugs, q, roleID)
+
+	return slugs, err
+}
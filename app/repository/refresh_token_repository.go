@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"fmt"
+	"time"
+)
+
+type RefreshTokenRepository interface {
+	Insert(dbctx db.DBCtx, rt model.RefreshToken) (model.RefreshToken, error)
+	Revoke(dbctx db.DBCtx, id int64) error
+	RevokeFamily(dbctx db.DBCtx, family string) error
+	RevokeAllByUserID(dbctx db.DBCtx, userID int64) error
+	GetByTokenHash(dbctx db.DBCtx, tokenHash string) (model.RefreshToken, error)
+}
+
+type refreshTokenRepository struct {
+}
+
+func NewRefreshTokenRepository() *refreshTokenRepository {
+	return &refreshTokenRepository{}
+}
+
+func (r *refreshTokenRepository) Insert(dbctx db.DBCtx, rt model.RefreshToken) (model.RefreshToken, error) {
+	var ID int64
+	rt.CreatedDate = time.Now().In(time.UTC)
+
+	if rt.Scopes == nil {
+		rt.Scopes = []string{}
+	}
+
+	paramQ := []interface{}{rt.UserID, rt.TokenHash, rt.Family, rt.ClientID, rt.Scopes, rt.ExpiredDate, rt.CreatedDate}
+	q := `insert into refresh_tokens (user_id, token_hash, family, client_id, scopes, expired_date, created_date) values ($1, $2, $3, $4, $5, $6, $7) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
+	rt.ID = ID
+
+	return rt, err
+}
+
+func (r *refreshTokenRepository) Revoke(dbctx db.DBCtx, id int64) error {
+	q := `update refresh_tokens set revoked_date = $1 where revoked_date is null and id = $2`
+	exec, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), id)
+	if err != nil {
+		return err
+	}
+	if exec.RowsAffected() <= 0 {
+		return fmt.Errorf(`%s`, "refresh token already revoked")
+	}
+
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeFamily(dbctx db.DBCtx, family string) error {
+	q := `update refresh_tokens set revoked_date = $1 where revoked_date is null and family = $2`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), family)
+
+	return err
+}
+
+func (r *refreshTokenRepository) RevokeAllByUserID(dbctx db.DBCtx, userID int64) error {
+	q := `update refresh_tokens set revoked_date = $1 where revoked_date is null and user_id = $2`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), userID)
+
+	return err
+}
+
+func (r *refreshTokenRepository) GetByTokenHash(dbctx db.DBCtx, tokenHash string) (model.RefreshToken, error) {
+	var rt model.RefreshToken
+
+	q := `select id, user_id, token_hash, family, client_id, scopes, expired_date, revoked_date, created_date from refresh_tokens where token_hash = $1 limit 1`
+	err := dbctx.DB.QueryRow(dbctx.Ctx, q, tokenHash).Scan(&rt.ID, &rt.UserID, &rt.TokenHash, &rt.Family, &rt.ClientID, &rt.Scopes, &rt.ExpiredDate, &rt.RevokedDate, &rt.CreatedDate)
+
+	return rt, err
+}
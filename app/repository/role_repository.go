@@ -14,6 +14,7 @@ import (
 type RoleRepository interface {
 	Insert(dbctx db.DBCtx, rl model.Role) (model.Role, error)
 	Update(dbctx db.DBCtx, rl model.Role) error
+	UpdateVersion(dbctx db.DBCtx, rl model.Role) error
 	Delete(dbctx db.DBCtx, code, deletedBy string) error
 	GetAll(dbctx db.DBCtx, f model.RoleFilter) ([]model.Role, error)
 	GetAllTotal(dbctx db.DBCtx, f model.RoleFilter) (int64, error)
@@ -53,6 +54,14 @@ func (r *roleRepository) Update(dbctx db.DBCtx, rl model.Role) error {
 	return err
 }
 
+func (r *roleRepository) UpdateVersion(dbctx db.DBCtx, rl model.Role) error {
+	paramQ := []interface{}{rl.UpdatedDate.Time, rl.UpdatedBy.String, rl.Version, rl.Code}
+	q := `update roles set updated_date = $1, updated_by = $2, version = $3 where deleted_date is null and code = $4`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, paramQ...)
+
+	return err
+}
+
 func (r *roleRepository) Delete(dbctx db.DBCtx, code, deletedBy string) error {
 	timeStamp := time.Now().In(time.UTC)
 	_, err := dbctx.TX.Exec(dbctx.Ctx,
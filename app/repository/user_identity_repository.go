@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type UserIdentityRepository interface {
+	Insert(dbctx db.DBCtx, u model.UserIdentity) (model.UserIdentity, error)
+	UpdateLastLogin(dbctx db.DBCtx, id int64) error
+	GetByProviderSubject(dbctx db.DBCtx, provider, subject string) (model.UserIdentity, error)
+}
+
+type userIdentityRepository struct {
+}
+
+func NewUserIdentityRepository() *userIdentityRepository {
+	return &userIdentityRepository{}
+}
+
+func (r *userIdentityRepository) Insert(dbctx db.DBCtx, u model.UserIdentity) (model.UserIdentity, error) {
+	var ID int64
+	u.CreatedDate = time.Now().In(time.UTC)
+	u.LastLoginDate.Time = u.CreatedDate
+	u.LastLoginDate.Valid = true
+
+	paramQ := []interface{}{u.UserID, u.Provider, u.Subject, u.Email, u.CreatedDate, u.LastLoginDate}
+	q := `insert into user_identities (user_id, provider, subject, email, created_date, last_login_date) values ($1, $2, $3, $4, $5, $6) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
+	u.ID = ID
+
+	return u, err
+}
+
+func (r *userIdentityRepository) UpdateLastLogin(dbctx db.DBCtx, id int64) error {
+	_, err := dbctx.TX.Exec(dbctx.Ctx, `update user_identities set last_login_date = $1 where id = $2`, time.Now().In(time.UTC), id)
+
+	return err
+}
+
+func (r *userIdentityRepository) GetByProviderSubject(dbctx db.DBCtx, provider, subject string) (model.UserIdentity, error) {
+	var u model.UserIdentity
+
+	q := `select * from user_identities where provider = $1 and subject = $2 limit 1`
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &u, q, provider, subject)
+
+	return u, err
+}
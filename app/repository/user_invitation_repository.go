@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"fmt"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type UserInvitationRepository interface {
+	Insert(dbctx db.DBCtx, inv model.UserInvitation) (model.UserInvitation, error)
+	Accept(dbctx db.DBCtx, id int64) error
+	RevokeActiveByUserID(dbctx db.DBCtx, userID int64) (int64, error)
+	GetActiveByTokenHash(dbctx db.DBCtx, tokenHash string) (model.UserInvitation, error)
+}
+
+type userInvitationRepository struct {
+}
+
+func NewUserInvitationRepository() *userInvitationRepository {
+	return &userInvitationRepository{}
+}
+
+func (r *userInvitationRepository) Insert(dbctx db.DBCtx, inv model.UserInvitation) (model.UserInvitation, error) {
+	var ID int64
+	inv.CreatedDate = time.Now().In(time.UTC)
+
+	q := `insert into user_invitations (user_id, token_hash, expired_date, created_date, created_by) values ($1, $2, $3, $4, $5) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, inv.UserID, inv.TokenHash, inv.ExpiredDate, inv.CreatedDate, inv.CreatedBy).Scan(&ID)
+	inv.ID = ID
+
+	return inv, err
+}
+
+func (r *userInvitationRepository) Accept(dbctx db.DBCtx, id int64) error {
+	q := `update user_invitations set accepted_date = $1 where id = $2 and accepted_date is null and revoked_date is null`
+	exec, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), id)
+	if err != nil {
+		return err
+	}
+	if exec.RowsAffected() <= 0 {
+		return fmt.Errorf(`%s`, "invitation has been used or revoked")
+	}
+
+	return nil
+}
+
+// RevokeActiveByUserID revoke the pending invitations of a user, returns the number of revoked invitations
+func (r *userInvitationRepository) RevokeActiveByUserID(dbctx db.DBCtx, userID int64) (int64, error) {
+	timeStamp := time.Now().In(time.UTC)
+	q := `update user_invitations set revoked_date = $1 where user_id = $2 and accepted_date is null and revoked_date is null and expired_date > $3`
+	exec, err := dbctx.TX.Exec(dbctx.Ctx, q, timeStamp, userID, timeStamp)
+	if err != nil {
+		return 0, err
+	}
+
+	return exec.RowsAffected(), nil
+}
+
+func (r *userInvitationRepository) GetActiveByTokenHash(dbctx db.DBCtx, tokenHash string) (model.UserInvitation, error) {
+	var inv model.UserInvitation
+
+	q := `select * from user_invitations where token_hash = $1 and accepted_date is null and revoked_date is null and expired_date > $2 limit 1`
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &inv, q, tokenHash, time.Now().In(time.UTC))
+
+	return inv, err
+}
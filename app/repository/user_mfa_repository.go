@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"time"
+)
+
+type UserMFARepository interface {
+	Upsert(dbctx db.DBCtx, u model.UserMFA) (model.UserMFA, error)
+	Update(dbctx db.DBCtx, u model.UserMFA) error
+	DeleteByUserID(dbctx db.DBCtx, userID int64) error
+	GetByUserID(dbctx db.DBCtx, userID int64) (model.UserMFA, error)
+	GetByUserIDForUpdate(dbctx db.DBCtx, userID int64) (model.UserMFA, error)
+}
+
+type userMFARepository struct {
+}
+
+func NewUserMFARepository() *userMFARepository {
+	return &userMFARepository{}
+}
+
+func (r *userMFARepository) Upsert(dbctx db.DBCtx, u model.UserMFA) (model.UserMFA, error) {
+	var ID int64
+	u.CreatedDate = time.Now().In(time.UTC)
+
+	paramQ := []interface{}{u.UserID, u.Secret, u.RecoveryCodes, u.Status, u.CreatedDate}
+	q := `insert into user_mfas (user_id, secret, recovery_codes, status, created_date) values ($1, $2, $3, $4, $5)
+		on conflict (user_id) do update set secret = excluded.secret, recovery_codes = excluded.recovery_codes, last_used_step = 0, status = excluded.status, updated_date = excluded.created_date
+		returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
+	u.ID = ID
+
+	return u, err
+}
+
+func (r *userMFARepository) Update(dbctx db.DBCtx, u model.UserMFA) error {
+	paramQ := []interface{}{u.RecoveryCodes, u.LastUsedStep, u.Status, time.Now().In(time.UTC), u.UserID}
+	q := `update user_mfas set recovery_codes = $1, last_used_step = $2, status = $3, updated_date = $4 where user_id = $5`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, paramQ...)
+
+	return err
+}
+
+func (r *userMFARepository) DeleteByUserID(dbctx db.DBCtx, userID int64) error {
+	_, err := dbctx.TX.Exec(dbctx.Ctx, `delete from user_mfas where user_id = $1`, userID)
+
+	return err
+}
+
+func (r *userMFARepository) GetByUserID(dbctx db.DBCtx, userID int64) (model.UserMFA, error) {
+	var u model.UserMFA
+
+	q := `select id, user_id, secret, recovery_codes, last_used_step, status, created_date, updated_date from user_mfas where user_id = $1 limit 1`
+	err := dbctx.DB.QueryRow(dbctx.Ctx, q, userID).Scan(&u.ID, &u.UserID, &u.Secret, &u.RecoveryCodes, &u.LastUsedStep, &u.Status, &u.CreatedDate, &u.UpdatedDate)
+
+	return u, err
+}
+
+// GetByUserIDForUpdate get the mfa of user and lock it until the transaction ends, verifications of the same user wait for each other
+func (r *userMFARepository) GetByUserIDForUpdate(dbctx db.DBCtx, userID int64) (model.UserMFA, error) {
+	var u model.UserMFA
+
+	q := `select id, user_id, secret, recovery_codes, last_used_step, status, created_date, updated_date from user_mfas where user_id = $1 limit 1 for update`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, userID).Scan(&u.ID, &u.UserID, &u.Secret, &u.RecoveryCodes, &u.LastUsedStep, &u.Status, &u.CreatedDate, &u.UpdatedDate)
+
+	return u, err
+}
@@ -3,13 +3,18 @@ package repository
 import (
 	"fiber-starter/app/model"
 	"fiber-starter/db"
+	"fmt"
 	"time"
 )
 
 type UserOTPRepository interface {
 	Insert(dbctx db.DBCtx, u model.UserOTP) (model.UserOTP, error)
-	UpdateOTP(dbctx db.DBCtx, u model.UserOTP) (model.UserOTP, error)
-	GetByEmailOrPhone(dbctx db.DBCtx, email, phone string) (model.UserOTP, error)
+	InvalidateByPurpose(dbctx db.DBCtx, email, phone, purpose string) error
+	GetActiveByEmailOrPhone(dbctx db.DBCtx, emailPhone, purpose string) (model.UserOTP, error)
+	GetActiveForUpdate(dbctx db.DBCtx, emailPhone, purpose string) (model.UserOTP, error)
+	DecrementAttempts(dbctx db.DBCtx, id int64) (int16, error)
+	Verify(dbctx db.DBCtx, id int64) error
+	Consume(dbctx db.DBCtx, id int64) error
 }
 
 type userOTPRepository struct {
@@ -21,53 +26,68 @@ func NewUserOTPRepository() *userOTPRepository {
 
 func (r *userOTPRepository) Insert(dbctx db.DBCtx, u model.UserOTP) (model.UserOTP, error) {
 	var ID int64
-	var timeStamp = time.Now().In(time.UTC)
-
-	otp, err := u.GenerateOTP()
-	if err != nil {
-		return u, err
-	}
-	u.OTP = otp
+	u.CreatedDate = time.Now().In(time.UTC)
 	u.ExpiredDate = u.GetExpiredDate()
 
-	paramQ := []interface{}{u.Email, u.Phone, u.OTP, u.ExpiredDate, timeStamp}
-	q := `insert into user_otps (email, phone, otp, expired_date, created_date) values ($1, $2, $3, $4, $5) returning id`
-	err = dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
-	if err != nil {
-		return u, err
-	}
+	paramQ := []interface{}{u.Email, u.Phone, u.Purpose, u.OTPHash, u.Salt, u.AttemptsLeft, u.ExpiredDate, u.CreatedDate}
+	q := `insert into user_otps (email, phone, purpose, otp_hash, salt, attempts_left, expired_date, created_date) values ($1, $2, $3, $4, $5, $6, $7, $8) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
 	u.ID = ID
 
 	return u, err
 }
 
-func (r *userOTPRepository) UpdateOTP(dbctx db.DBCtx, u model.UserOTP) (model.UserOTP, error) {
-	var ID int64
-	var timeStamp = time.Now().In(time.UTC)
+func (r *userOTPRepository) InvalidateByPurpose(dbctx db.DBCtx, email, phone, purpose string) error {
+	q := `update user_otps set used_date = $1 where used_date is null and (email = $2 or phone = $3) and purpose = $4`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), email, phone, purpose)
 
-	otp, err := u.GenerateOTP()
-	if err != nil {
-		return u, err
-	}
-	u.OTP = otp
-	u.ExpiredDate = u.GetExpiredDate()
+	return err
+}
 
-	paramQ := []interface{}{u.OTP, u.ExpiredDate, timeStamp, u.Email, u.Phone}
-	q := `update user_otps set otp = $1, expired_date = $2, updated_date = $3 where (email = $4 or phone = $5) returning id`
-	err = dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
-	if err != nil {
-		return u, err
-	}
-	u.ID = ID
+func (r *userOTPRepository) GetActiveByEmailOrPhone(dbctx db.DBCtx, emailPhone, purpose string) (model.UserOTP, error) {
+	var u model.UserOTP
+
+	q := `select id, email, phone, purpose, otp_hash, salt, attempts_left, expired_date, verified_date, used_date, created_date from user_otps where used_date is null and (email = $1 or phone = $1) and purpose = $2 order by id desc limit 1`
+	err := dbctx.DB.QueryRow(dbctx.Ctx, q, emailPhone, purpose).Scan(&u.ID, &u.Email, &u.Phone, &u.Purpose, &u.OTPHash, &u.Salt, &u.AttemptsLeft, &u.ExpiredDate, &u.VerifiedDate, &u.UsedDate, &u.CreatedDate)
 
 	return u, err
 }
 
-func (r userOTPRepository) GetByEmailOrPhone(dbctx db.DBCtx, email, phone string) (model.UserOTP, error) {
+// GetActiveForUpdate get the active otp and lock it until the transaction ends, checks of the same otp wait for each other
+func (r *userOTPRepository) GetActiveForUpdate(dbctx db.DBCtx, emailPhone, purpose string) (model.UserOTP, error) {
 	var u model.UserOTP
 
-	q := `select id, email, phone, otp, expired_date from user_otps where (email = $1 or phone = $2)`
-	err := dbctx.DB.QueryRow(dbctx.Ctx, q, email, phone).Scan(&u.ID, &u.Email, &u.Phone, &u.OTP, &u.ExpiredDate)
+	q := `select id, email, phone, purpose, otp_hash, salt, attempts_left, expired_date, verified_date, used_date, created_date from user_otps where used_date is null and (email = $1 or phone = $1) and purpose = $2 order by id desc limit 1 for update`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, emailPhone, purpose).Scan(&u.ID, &u.Email, &u.Phone, &u.Purpose, &u.OTPHash, &u.Salt, &u.AttemptsLeft, &u.ExpiredDate, &u.VerifiedDate, &u.UsedDate, &u.CreatedDate)
 
 	return u, err
 }
+
+func (r *userOTPRepository) DecrementAttempts(dbctx db.DBCtx, id int64) (int16, error) {
+	var attemptsLeft int16
+
+	q := `update user_otps set attempts_left = attempts_left - 1 where used_date is null and attempts_left > 0 and id = $1 returning attempts_left`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, id).Scan(&attemptsLeft)
+
+	return attemptsLeft, err
+}
+
+func (r *userOTPRepository) Verify(dbctx db.DBCtx, id int64) error {
+	q := `update user_otps set verified_date = $1 where used_date is null and id = $2`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), id)
+
+	return err
+}
+
+func (r *userOTPRepository) Consume(dbctx db.DBCtx, id int64) error {
+	q := `update user_otps set used_date = $1 where used_date is null and id = $2`
+	exec, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), id)
+	if err != nil {
+		return err
+	}
+	if exec.RowsAffected() <= 0 {
+		return fmt.Errorf(`%s`, "otp already used")
+	}
+
+	return err
+}
@@ -15,7 +15,11 @@ type UserRepository interface {
 	Update(dbctx db.DBCtx, u model.User) error
 	Delete(dbctx db.DBCtx, code, deletedBy string) error
 	UpdatePasswordByEmailOrPhone(dbctx db.DBCtx, password, emailPhone string) error
+	UpdatePasswordHash(dbctx db.DBCtx, id int64, password string) error
+	UpdateEmail(dbctx db.DBCtx, id int64, email, updatedBy string) error
+	UpdatePhone(dbctx db.DBCtx, id int64, phone, updatedBy string) error
 	UpdateStatusByEmailOrPhone(dbctx db.DBCtx, status bool, emailPhone string) error
+	GetByID(dbctx db.DBCtx, id int64) (model.User, error)
 	GetByCode(dbctx db.DBCtx, code string) (model.User, error)
 	GetByEmail(dbctx db.DBCtx, email string) (model.User, error)
 	GetByEmailOrPhone(dbctx db.DBCtx, emailPhone string) (model.User, error)
@@ -35,9 +39,9 @@ func (r *userRepository) Insert(dbctx db.DBCtx, u model.User) (model.User, error
 	var ID int64
 	u.CreatedDate = time.Now().In(time.UTC)
 
-	paramQ := []interface{}{u.Code, u.RoleID, u.Role, u.Name, u.Email, u.Phone, u.Password, u.Address.String, u.Img.String, u.RememberToken.String, u.Status, u.CreatedDate, u.CreatedBy, 1}
+	paramQ := []interface{}{u.Code, u.RoleID, u.Role, u.Name, u.Email, u.Phone, u.Password, u.Address.String, u.Img.String, u.RememberToken.String, u.Status, u.CreatedDate, u.CreatedBy, 1, u.MustChangePassword}
 
-	q := `insert into users (code, role_id, role, name, email, phone, password, address, img, remember_token, status, created_date, created_by, version) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14) returning id`
+	q := `insert into users (code, role_id, role, name, email, phone, password, address, img, remember_token, status, created_date, created_by, version, must_change_password) values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15) returning id`
 	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
 	if err != nil {
 		return u, err
@@ -68,7 +72,7 @@ func (r *userRepository) Delete(dbctx db.DBCtx, code, deletedBy string) error {
 func (r *userRepository) UpdatePasswordByEmailOrPhone(dbctx db.DBCtx, password, emailPhone string) error {
 	paramQ := []interface{}{password, time.Now().In(time.UTC), emailPhone, emailPhone}
 
-	q := `update users set password = $1, updated_date = $2 where deleted_date is null and (email = $3 or phone = $4)`
+	q := `update users set password = $1, must_change_password = false, updated_date = $2 where deleted_date is null and (email = $3 or phone = $4)`
 	exec, err := dbctx.TX.Exec(dbctx.Ctx, q, paramQ...)
 	if exec.RowsAffected() <= 0 {
 		return fmt.Errorf(`%s`, "update password failed")
@@ -77,6 +81,28 @@ func (r *userRepository) UpdatePasswordByEmailOrPhone(dbctx db.DBCtx, password,
 	return err
 }
 
+// UpdatePasswordHash replace the hash of the same password, the password change state is kept
+func (r *userRepository) UpdatePasswordHash(dbctx db.DBCtx, id int64, password string) error {
+	q := `update users set password = $1 where deleted_date is null and id = $2`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, password, id)
+
+	return err
+}
+
+func (r *userRepository) UpdateEmail(dbctx db.DBCtx, id int64, email, updatedBy string) error {
+	q := `update users set email = $1, updated_date = $2, updated_by = $3, version = version + 1 where deleted_date is null and id = $4`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, email, time.Now().In(time.UTC), updatedBy, id)
+
+	return err
+}
+
+func (r *userRepository) UpdatePhone(dbctx db.DBCtx, id int64, phone, updatedBy string) error {
+	q := `update users set phone = $1, updated_date = $2, updated_by = $3, version = version + 1 where deleted_date is null and id = $4`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, phone, time.Now().In(time.UTC), updatedBy, id)
+
+	return err
+}
+
 func (r *userRepository) UpdateStatusByEmailOrPhone(dbctx db.DBCtx, status bool, emailPhone string) error {
 	paramQ := []interface{}{status, nil, time.Now().In(time.UTC), emailPhone, emailPhone}
 
@@ -89,6 +115,15 @@ func (r *userRepository) UpdateStatusByEmailOrPhone(dbctx db.DBCtx, status bool,
 	return err
 }
 
+func (r *userRepository) GetByID(dbctx db.DBCtx, id int64) (model.User, error) {
+	var u model.User
+
+	q := `select * from users where deleted_date is null and id = $1 limit 1`
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &u, q, id)
+
+	return u, err
+}
+
 func (r *userRepository) GetByCode(dbctx db.DBCtx, code string) (model.User, error) {
 	var u model.User
 
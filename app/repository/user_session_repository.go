@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/db"
+	"time"
+
+	"github.com/georgysavva/scany/pgxscan"
+)
+
+type UserSessionRepository interface {
+	Insert(dbctx db.DBCtx, us model.UserSession) (model.UserSession, error)
+	UpdateLastSeen(dbctx db.DBCtx, family string) error
+	Revoke(dbctx db.DBCtx, id int64) error
+	RevokeByFamily(dbctx db.DBCtx, family string) error
+	RevokeAllByUserID(dbctx db.DBCtx, userID int64) error
+	GetByID(dbctx db.DBCtx, id, userID int64) (model.UserSession, error)
+	GetActiveByUserID(dbctx db.DBCtx, userID int64) ([]model.UserSession, error)
+}
+
+type userSessionRepository struct {
+}
+
+func NewUserSessionRepository() *userSessionRepository {
+	return &userSessionRepository{}
+}
+
+func (r *userSessionRepository) Insert(dbctx db.DBCtx, us model.UserSession) (model.UserSession, error) {
+	var ID int64
+	us.CreatedDate = time.Now().In(time.UTC)
+	us.LastSeenDate = us.CreatedDate
+
+	paramQ := []interface{}{us.UserID, us.Family, us.DeviceName, us.UserAgent, us.IP, us.CreatedDate, us.LastSeenDate}
+	q := `insert into user_sessions (user_id, family, device_name, user_agent, ip, created_date, last_seen_date) values ($1, $2, $3, $4, $5, $6, $7) returning id`
+	err := dbctx.TX.QueryRow(dbctx.Ctx, q, paramQ...).Scan(&ID)
+	us.ID = ID
+
+	return us, err
+}
+
+// UpdateLastSeen write outside transaction, it is touched by every authenticated request
+func (r *userSessionRepository) UpdateLastSeen(dbctx db.DBCtx, family string) error {
+	q := `update user_sessions set last_seen_date = $1 where family = $2 and revoked_date is null`
+	_, err := dbctx.DB.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), family)
+
+	return err
+}
+
+func (r *userSessionRepository) Revoke(dbctx db.DBCtx, id int64) error {
+	q := `update user_sessions set revoked_date = $1 where id = $2 and revoked_date is null`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), id)
+
+	return err
+}
+
+func (r *userSessionRepository) RevokeByFamily(dbctx db.DBCtx, family string) error {
+	q := `update user_sessions set revoked_date = $1 where family = $2 and revoked_date is null`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), family)
+
+	return err
+}
+
+func (r *userSessionRepository) RevokeAllByUserID(dbctx db.DBCtx, userID int64) error {
+	q := `update user_sessions set revoked_date = $1 where user_id = $2 and revoked_date is null`
+	_, err := dbctx.TX.Exec(dbctx.Ctx, q, time.Now().In(time.UTC), userID)
+
+	return err
+}
+
+func (r *userSessionRepository) GetByID(dbctx db.DBCtx, id, userID int64) (model.UserSession, error) {
+	var us model.UserSession
+
+	q := `select * from user_sessions where id = $1 and user_id = $2 and revoked_date is null limit 1`
+	err := pgxscan.Get(dbctx.Ctx, dbctx.DB, &us, q, id, userID)
+
+	return us, err
+}
+
+func (r *userSessionRepository) GetActiveByUserID(dbctx db.DBCtx, userID int64) ([]model.UserSession, error) {
+	var sessions []model.UserSession
+
+	q := `select * from user_sessions where user_id = $1 and revoked_date is null order by last_seen_date desc`
+	err := pgxscan.Select(dbctx.Ctx, dbctx.DB, &sessions, q, userID)
+
+	return sessions, err
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	apiClientIDAlphabet     = "abcdefghijklmnopqrstuvwxyz0123456789"
+	apiClientSecretAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var ErrInvalidClient = errors.New("invalid client credential")
+
+type ApiClientService interface {
+	FindClient(dbctx db.DBCtx, code string) (model.ApiClient, error)
+	CreateClient(dbctx db.DBCtx, req requests.ApiClientCreateRequest, handlerBy string, catalogue []string) (model.ApiClient, string, error)
+	UpdateClient(dbctx db.DBCtx, req requests.ApiClientUpdateRequest, handlerBy, code string, catalogue []string) (model.ApiClient, error)
+	RotateSecret(dbctx db.DBCtx, req requests.ApiClientSecretRequest, handlerBy, code string) (model.ApiClient, string, error)
+	DeleteClient(dbctx db.DBCtx, handlerBy, code string) error
+	FindAllClient(dbctx db.DBCtx, c *fiber.Ctx) ([]model.ApiClient, int64, common.PaginateQueryOffset, error)
+	Authenticate(dbctx db.DBCtx, clientID, secret string) (model.ApiClient, error)
+}
+
+type apiClientService struct {
+	clientR repository.ApiClientRepository
+	roleR   repository.RoleRepository
+}
+
+func NewApiClientService(client repository.ApiClientRepository, role repository.RoleRepository) *apiClientService {
+	return &apiClientService{client, role}
+}
+
+func (s *apiClientService) FindClient(dbctx db.DBCtx, code string) (model.ApiClient, error) {
+	// Check code
+	if len(code) <= 0 {
+		return model.ApiClient{}, errors.New("invalid code")
+	}
+
+	return s.clientR.GetByCode(dbctx, code)
+}
+
+func (s *apiClientService) CreateClient(dbctx db.DBCtx, req requests.ApiClientCreateRequest, handlerBy string, catalogue []string) (model.ApiClient, string, error) {
+	// Check allowed roles and scopes
+	if err := s.checkRoles(dbctx, req.AllowedRoles); err != nil {
+		return model.ApiClient{}, "", err
+	}
+	if err := checkScopes(req.Scopes, catalogue); err != nil {
+		return model.ApiClient{}, "", err
+	}
+
+	// Generate credential
+	clientID, err := utils.RandomString(24, apiClientIDAlphabet)
+	if err != nil {
+		return model.ApiClient{}, "", err
+	}
+	secret, err := utils.RandomString(48, apiClientSecretAlphabet)
+	if err != nil {
+		return model.ApiClient{}, "", err
+	}
+
+	// Insert client
+	client, err := s.clientR.Insert(dbctx, model.ApiClient{
+		Code:         common.CodeGenerator(model.API_CLIENT_PREFIX, 6),
+		Name:         req.Name,
+		ClientID:     clientID,
+		SecretHash:   utils.HashToken(secret),
+		Channel:      req.Channel,
+		AllowedRoles: allowedRolesOf(req.Channel, req.AllowedRoles),
+		CallbackURLs: nonNilStrings(req.CallbackURLs),
+		Scopes:       nonNilStrings(req.Scopes),
+		Status:       *req.Status,
+		CreatedBy:    handlerBy,
+	})
+
+	return client, secret, err
+}
+
+func (s *apiClientService) UpdateClient(dbctx db.DBCtx, req requests.ApiClientUpdateRequest, handlerBy, code string, catalogue []string) (model.ApiClient, error) {
+	// Find client and check version
+	client, err := s.checkVersion(dbctx, req.Version, code)
+	if err != nil {
+		return client, err
+	}
+
+	// Check allowed roles and scopes
+	if err := s.checkRoles(dbctx, req.AllowedRoles); err != nil {
+		return client, err
+	}
+	if err := checkScopes(req.Scopes, catalogue); err != nil {
+		return client, err
+	}
+
+	// Update client
+	client.Name = req.Name
+	client.Channel = req.Channel
+	client.AllowedRoles = allowedRolesOf(req.Channel, req.AllowedRoles)
+	client.CallbackURLs = nonNilStrings(req.CallbackURLs)
+	client.Scopes = nonNilStrings(req.Scopes)
+	client.Status = *req.Status
+	client.Version++
+	client.UpdatedDate = sql.NullTime{Valid: true, Time: time.Now().In(time.UTC)}
+	client.UpdatedBy = sql.NullString{Valid: true, String: handlerBy}
+	err = s.clientR.Update(dbctx, client)
+
+	return client, err
+}
+
+func (s *apiClientService) RotateSecret(dbctx db.DBCtx, req requests.ApiClientSecretRequest, handlerBy, code string) (model.ApiClient, string, error) {
+	// Find client and check version
+	client, err := s.checkVersion(dbctx, req.Version, code)
+	if err != nil {
+		return client, "", err
+	}
+
+	// Generate new secret, the old one stops working right away
+	secret, err := utils.RandomString(48, apiClientSecretAlphabet)
+	if err != nil {
+		return client, "", err
+	}
+	client.SecretHash = utils.HashToken(secret)
+	client.Version++
+	client.UpdatedDate = sql.NullTime{Valid: true, Time: time.Now().In(time.UTC)}
+	client.UpdatedBy = sql.NullString{Valid: true, String: handlerBy}
+	err = s.clientR.UpdateSecret(dbctx, client)
+
+	return client, secret, err
+}
+
+func (s *apiClientService) DeleteClient(dbctx db.DBCtx, handlerBy, code string) error {
+	// Check code
+	if len(code) <= 0 {
+		return errors.New("invalid code")
+	}
+
+	return s.clientR.Delete(dbctx, code, handlerBy)
+}
+
+func (s *apiClientService) FindAllClient(dbctx db.DBCtx, c *fiber.Ctx) ([]model.ApiClient, int64, common.PaginateQueryOffset, error) {
+	// Define variable
+	var clientList []model.ApiClient
+	var total int64
+
+	// Set filter
+	var f model.ApiClientFilter
+	search := c.Query("search")
+	if len(search) > 0 {
+		f.Search = search
+	}
+	status := c.Query("status")
+	if len(status) > 0 {
+		f.Status = status
+	}
+	channel := c.Query("channel")
+	if len(channel) > 0 {
+		if err := model.CheckValidClientChannel(channel); err != nil {
+			return clientList, total, common.PaginateQueryOffset{}, err
+		}
+		f.Channel = channel
+	}
+
+	// Define pagination
+	pg, err := common.GetPaginateQueryOffset(c)
+	if err != nil {
+		return clientList, total, pg, err
+	}
+	f.Paging = pg
+
+	// Get data
+	clientList, err = s.clientR.GetAll(dbctx, f)
+	if err != nil {
+		return clientList, total, pg, err
+	}
+
+	// Get total data
+	total, err = s.clientR.GetAllTotal(dbctx, f)
+
+	return clientList, total, pg, err
+}
+
+func (s *apiClientService) Authenticate(dbctx db.DBCtx, clientID, secret string) (model.ApiClient, error) {
+	if len(clientID) <= 0 || len(secret) <= 0 {
+		return model.ApiClient{}, ErrInvalidClient
+	}
+
+	// Find active client
+	client, err := s.clientR.GetByClientID(dbctx, clientID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return client, ErrInvalidClient
+		}
+		return client, err
+	}
+
+	// Compare secret hash
+	if subtle.ConstantTimeCompare([]byte(client.SecretHash), []byte(utils.HashToken(secret))) != 1 {
+		return model.ApiClient{}, ErrInvalidClient
+	}
+
+	return client, nil
+}
+
+// checkVersion find client by code and check its version
+func (s *apiClientService) checkVersion(dbctx db.DBCtx, reqVersion int, code string) (model.ApiClient, error) {
+	client, err := s.FindClient(dbctx, code)
+	if err != nil {
+		return client, err
+	}
+	if client.Version != int32(reqVersion) {
+		return client, errors.New("version is not match")
+	}
+
+	return client, nil
+}
+
+// checkRoles check every allowed role exists
+func (s *apiClientService) checkRoles(dbctx db.DBCtx, roles []string) error {
+	for _, role := range roles {
+		if _, err := s.roleR.GetIDBySlug(dbctx, role); err != nil {
+			return fmt.Errorf(`invalid role %s`, role)
+		}
+	}
+
+	return nil
+}
+
+// checkScopes check every scope is a permission of the catalogue
+func checkScopes(scopes, catalogue []string) error {
+	arrStr := new(common.ArrStr)
+	for _, scope := range scopes {
+		if exists, _ := arrStr.InArray(scope, catalogue); !exists {
+			return fmt.Errorf(`invalid scope %s`, scope)
+		}
+	}
+
+	return nil
+}
+
+// allowedRolesOf the requested roles, or the default roles of the channel
+func allowedRolesOf(channel string, roles []string) []string {
+	if len(roles) <= 0 {
+		return model.DefaultAllowedRoles(channel)
+	}
+
+	return roles
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
@@ -6,7 +6,6 @@ import (
 	"fiber-starter/app/api/requests"
 	"fiber-starter/app/model"
 	"fiber-starter/app/repository"
-	"fiber-starter/config"
 	"fiber-starter/db"
 	"fiber-starter/pkg/common"
 	"fiber-starter/pkg/utils"
@@ -15,102 +14,139 @@ import (
 	"time"
 
 	"github.com/jackc/pgx/v4"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService interface {
-	GenerateOTPToken(dbctx db.DBCtx, channel, email, phone string) (string, error)
-	SendOTPToken(cfg *config.RabbitMQ, emailPhone, channel, usedFor, otpToken, via string) error
-	Registration(dbctx db.DBCtx, req requests.RegisterRequest, channel string, rabbitCfg *config.RabbitMQ) (model.User, string, error)
-	AuthLogin(dbctx db.DBCtx, req requests.LoginRequest, channel string, rabbitCfg *config.RabbitMQ) (model.User, string, error)
-	SendOTPTokenByType(dbctx db.DBCtx, emailPhone, channel, sendType string, rabbitCfg *config.RabbitMQ) (string, bool, error)
-	ChangePassword(dbctx db.DBCtx, req requests.ResetPasswordRequest, channel string) error
-	OTPTokenValidation(dbctx db.DBCtx, req requests.ValidateOTPTokenRequest, channel, sendType string) error
+	GenerateOTPToken(dbctx db.DBCtx, channel, usedFor string, user model.User) (string, error)
+	SendOTPToken(dbctx db.DBCtx, user model.User, client model.ApiClient, usedFor, otpToken, via string) error
+	Registration(dbctx db.DBCtx, req requests.RegisterRequest, client model.ApiClient) (model.User, error)
+	AuthLogin(dbctx db.DBCtx, req requests.LoginRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error)
+	IssueLoginToken(dbctx db.DBCtx, user model.User, device model.Device) (model.AuthToken, error)
+	RefreshToken(dbctx db.DBCtx, req requests.RefreshTokenRequest) (model.User, model.AuthToken, error)
+	Logout(dbctx db.DBCtx, req requests.LogoutRequest, tokenMetaData *utils.TokenMetaData) error
+	LogoutAll(dbctx db.DBCtx, userID int64, code string) error
+	MFAVerify(dbctx db.DBCtx, req requests.MFAVerifyRequest, device model.Device) (model.User, model.AuthToken, error)
+	SendOTPTokenByType(dbctx db.DBCtx, emailPhone string, client model.ApiClient, sendType string) (bool, error)
+	ChangePassword(dbctx db.DBCtx, req requests.ResetPasswordRequest, client model.ApiClient) error
+	PasswordChange(dbctx db.DBCtx, req requests.PasswordChangeRequest, device model.Device) (model.User, model.AuthToken, error)
+	AcceptInvitation(dbctx db.DBCtx, req requests.AcceptInvitationRequest) error
+	OTPTokenValidation(dbctx db.DBCtx, req requests.ValidateOTPTokenRequest, client model.ApiClient, sendType string) error
+	ConsumeOTPToken(dbctx db.DBCtx, channel, usedFor, emailPhone, otpToken string) (model.User, error)
+	PasswordlessLogin(dbctx db.DBCtx, req requests.ValidateOTPTokenRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error)
 }
 
+var (
+	ErrInvalidCredential   = errors.New("invalid user credential")
+	ErrInvalidOTP          = errors.New("invalid otp")
+	ErrOTPAttemptsExceeded = errors.New("otp attempts exceeded, please request a new otp")
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, please login again")
+	ErrInvalidInvitation   = errors.New("invalid or expired invitation")
+
+	ErrPasswordChangeNotRequired = errors.New("password change is not required")
+)
+
 type authService struct {
-	userR repository.UserRepository
-	roleR repository.RoleRepository
-	otpR  repository.UserOTPRepository
+	userR       repository.UserRepository
+	roleR       repository.RoleRepository
+	otpR        repository.UserOTPRepository
+	refreshR    repository.RefreshTokenRepository
+	mfaS        MFAService
+	passwordS   PasswordService
+	invitationR repository.UserInvitationRepository
+	sessionS    SessionService
+	notifyS     NotificationService
 }
 
-func NewAuthService(user repository.UserRepository, role repository.RoleRepository, otp repository.UserOTPRepository) *authService {
-	return &authService{user, role, otp}
+func NewAuthService(user repository.UserRepository, role repository.RoleRepository, otp repository.UserOTPRepository, refresh repository.RefreshTokenRepository, mfa MFAService, password PasswordService, invitation repository.UserInvitationRepository, session SessionService, notify NotificationService) *authService {
+	return &authService{user, role, otp, refresh, mfa, password, invitation, session, notify}
 }
 
-func (s *authService) GenerateOTPToken(dbctx db.DBCtx, channel, email, phone string) (string, error) {
-	// Generate otpToken channel web adm cms
+func (s *authService) GenerateOTPToken(dbctx db.DBCtx, channel, usedFor string, user model.User) (string, error) {
+	// Generate single-use link token of the usage for channel web adm cms
 	if channel == utils.ChannelWeb {
-		token, _, err := utils.GenerateJWT(0, "", phone, email, "")
+		token, _, err := utils.GenerateVerificationJWT(usedFor, user.ID, user.Code, user.Phone, user.Email, model.VERIFY_TOKEN_EXPIRED_TIME*time.Minute)
 		return token, err
 	}
 
-	// Define data otp exist
-	var otp string
-	userOTP, err := s.otpR.GetByEmailOrPhone(dbctx, email, phone)
-	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
-		return otp, err
+	// Generate otp
+	otp, err := model.GenerateOTP()
+	if err != nil {
+		return "", err
+	}
+	userOTP := model.UserOTP{
+		Email:        user.Email,
+		Phone:        user.Phone,
+		Purpose:      usedFor,
+		AttemptsLeft: model.OTP_MAX_ATTEMPTS,
+	}
+	err = userOTP.SetOTP(otp)
+	if err != nil {
+		return "", err
 	}
 
-	// Adjustment send otp in one row
-	if userOTP.ID > 0 {
-		userOTP, err = s.otpR.UpdateOTP(dbctx, userOTP)
-	} else {
-		userOTP, err = s.otpR.Insert(dbctx, model.UserOTP{
-			Email: email,
-			Phone: phone,
-		})
+	// A new otp replaces the previous otp of the usage
+	err = s.otpR.InvalidateByPurpose(dbctx, user.Email, user.Phone, usedFor)
+	if err != nil {
+		return "", err
 	}
-	otp = userOTP.OTP
+	_, err = s.otpR.Insert(dbctx, userOTP)
 
 	return otp, err
 }
 
-func (s *authService) SendOTPToken(cfg *config.RabbitMQ, emailPhone, channel, usedFor, otpToken, via string) error {
+// SendOTPToken notify the otp or link to the email or phone of the user, which may be an address being verified
+func (s *authService) SendOTPToken(dbctx db.DBCtx, user model.User, client model.ApiClient, usedFor, otpToken, via string) error {
 	// Define data mail
 	dataMail := utils.DataEmailToken{ExpiredTime: model.TOKEN_EXPIRED_TIME}
-	if channel == utils.ChannelApp {
+	if client.Channel == utils.ChannelApp {
 		dataMail.Title = "OTP"
 		dataMail.IsChannelApp = true
 		dataMail.Description = "Please input the 6 digit code"
 		dataMail.TokenURL = otpToken
-	} else if channel == utils.ChannelWeb {
+	} else if client.Channel == utils.ChannelWeb {
 		dataMail.Title = "Link"
 		dataMail.IsChannelApp = false
+		dataMail.ExpiredTime = model.VERIFY_TOKEN_EXPIRED_TIME
 		dataMail.Description = "Please click the link"
-		dataMail.TokenURL = fmt.Sprintf("https://sample.com/auth/%s?token=%s", usedFor, otpToken)
+		dataMail.TokenURL = fmt.Sprintf("%s/auth/%s?token=%s", client.CallbackURL(), usedFor, otpToken)
 	}
 
-	// Push email
+	// Notify through the channel of the address
 	if via == model.OTP_VIA_EMAIL {
-		return utils.PushQueueMail(cfg, utils.CMDQueueSendMail, utils.SetMailData([]string{emailPhone}, usedFor, dataMail))
+		return s.notifyS.Notify(dbctx, usedFor, user, dataMail, model.NOTIFY_CHANNEL_EMAIL)
 	} else if via == model.OTP_VIA_PHONE {
-		return errors.New("sorry, sms gateway not yet available")
+		return s.notifyS.Notify(dbctx, usedFor, user, dataMail, model.NOTIFY_CHANNEL_SMS)
 	}
 
 	return nil
 }
 
-func (s *authService) Registration(dbctx db.DBCtx, req requests.RegisterRequest, channel string, rabbitCfg *config.RabbitMQ) (model.User, string, error) {
+func (s *authService) Registration(dbctx db.DBCtx, req requests.RegisterRequest, client model.ApiClient) (model.User, error) {
 	// Define data
 	var user model.User
-	var otpToken string
 
 	// Compare password
 	if req.Password != req.ConfirmPassword {
-		return user, otpToken, fmt.Errorf("%s", "password is not match.")
+		return user, fmt.Errorf("%s", "password is not match.")
 	}
 
 	// Phone validation
 	_, _, validPhone := common.IsPhone(req.Phone)
 	if !validPhone {
-		return user, otpToken, fmt.Errorf(`phone %s is invalid`, req.Phone)
+		return user, fmt.Errorf(`phone %s is invalid`, req.Phone)
+	}
+
+	// Check password policy
+	err := s.passwordS.Validate(dbctx, req.Password, model.User{Name: req.Name, Email: req.Email})
+	if err != nil {
+		return user, err
 	}
 
 	// Hash password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+	passwordHash, err := s.passwordS.Hash(req.Password)
 	if err != nil {
-		return user, otpToken, err
+		return user, err
 	}
 
 	// Set data
@@ -122,117 +158,356 @@ func (s *authService) Registration(dbctx db.DBCtx, req requests.RegisterRequest,
 		Email:     req.Email,
 		Role:      model.ROLE_CUST,
 		Phone:     req.Phone,
-		Password:  string(passwordHash),
+		Password:  passwordHash,
 		CreatedBy: code,
 	}
 
 	// Set role slug
 	roleID, err := s.roleR.GetIDBySlug(dbctx, user.Role)
 	if err != nil {
-		return user, otpToken, err
+		return user, err
 	}
 	user.RoleID = int32(roleID)
 
 	// Insert data
 	userInserted, err := s.userR.Insert(dbctx, user)
 	if err != nil {
-		return user, otpToken, err
+		return user, err
+	}
+	err = s.passwordS.Remember(dbctx, userInserted.ID, passwordHash)
+	if err != nil {
+		return user, err
 	}
 
-	// Set / get otp user
-	otpToken, err = s.GenerateOTPToken(dbctx, channel, userInserted.Email, userInserted.Phone)
+	// Set / get otp user, it is only sent to the email
+	otpToken, err := s.GenerateOTPToken(dbctx, client.Channel, utils.MAIL_FOR_USERACTIVATION, userInserted)
 	if err != nil {
-		return user, otpToken, err
+		return user, err
 	}
 
 	// Send otp to queue mail
-	err = s.SendOTPToken(rabbitCfg, userInserted.Email, channel, utils.MAIL_FOR_USERACTIVATION, otpToken, model.OTP_VIA_EMAIL)
+	err = s.SendOTPToken(dbctx, userInserted, client, utils.MAIL_FOR_USERACTIVATION, otpToken, model.OTP_VIA_EMAIL)
 	if err != nil {
-		return user, otpToken, err
+		return user, err
 	}
 
-	return userInserted, otpToken, err
+	return userInserted, err
 }
 
-func (s *authService) AuthLogin(dbctx db.DBCtx, req requests.LoginRequest, channel string, rabbitCfg *config.RabbitMQ) (model.User, string, error) {
+func (s *authService) AuthLogin(dbctx db.DBCtx, req requests.LoginRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error) {
 	// Define data
-	var otpToken string
+	var token model.AuthToken
 
 	// Get user
 	user, err := s.userR.GetByEmail(dbctx, req.Email)
 	if err != nil {
 		if err.Error() == pgx.ErrNoRows.Error() {
-			err = fmt.Errorf(`invalid user credential`)
+			err = ErrInvalidCredential
 		}
-		return user, otpToken, err
+		return user, token, err
 	}
 
 	// Validate pass
-	byteHash := []byte(user.Password)
-	if err := bcrypt.CompareHashAndPassword(byteHash, []byte(req.Password)); err != nil {
-		return user, otpToken, fmt.Errorf(`invalid user credential`)
+	match, rehash, err := utils.VerifyPassword(user.Password, req.Password)
+	if err != nil || !match {
+		return user, token, ErrInvalidCredential
+	}
+
+	// Replace outdated hash of the password
+	if rehash {
+		passwordHash, err := utils.HashPassword(req.Password)
+		if err != nil {
+			return user, token, err
+		}
+		err = s.userR.UpdatePasswordHash(dbctx, user.ID, passwordHash)
+		if err != nil {
+			return user, token, err
+		}
+		user.Password = passwordHash
 	}
 
-	// Adjustment login user role channel
-	err = model.CheckValidChannelRole(channel, user.Role)
+	// Adjustment login user role of client
+	err = client.CheckAllowedRole(user.Role)
 	if err != nil {
-		return user, otpToken, err
+		return user, token, err
 	}
 
 	// Adjustment user status
 	if user.Status {
-		// Generate token
-		token, _, err := utils.GenerateJWT(user.ID, user.Code, user.Phone, user.Email, user.Role)
+		token, err = s.IssueLoginToken(dbctx, user, device)
 		if err != nil {
-			return user, otpToken, err
+			return user, token, err
+		}
+		if len(token.AccessToken) > 0 {
+			user.RememberToken = sql.NullString{Valid: true, String: token.AccessToken}
 		}
-		user.RememberToken = sql.NullString{Valid: true, String: token}
 	} else {
-		// Set / get otp user
-		otpToken, err = s.GenerateOTPToken(dbctx, channel, user.Email, user.Phone)
+		// Set / get otp user, it is only sent to the email
+		otpToken, err := s.GenerateOTPToken(dbctx, client.Channel, utils.MAIL_FOR_USERACTIVATION, user)
 		if err != nil {
-			return user, otpToken, err
+			return user, token, err
 		}
 
 		// Send otp to queue mail
-		err = s.SendOTPToken(rabbitCfg, user.Email, channel, utils.MAIL_FOR_USERACTIVATION, otpToken, model.OTP_VIA_EMAIL)
+		err = s.SendOTPToken(dbctx, user, client, utils.MAIL_FOR_USERACTIVATION, otpToken, model.OTP_VIA_EMAIL)
 		if err != nil {
-			return user, otpToken, err
+			return user, token, err
 		}
 	}
 
-	return user, otpToken, err
+	return user, token, err
 }
 
-func (s *authService) SendOTPTokenByType(dbctx db.DBCtx, emailPhone, channel, sendType string, rabbitCfg *config.RabbitMQ) (string, bool, error) {
+func (s *authService) RefreshToken(dbctx db.DBCtx, req requests.RefreshTokenRequest) (model.User, model.AuthToken, error) {
 	// Define data
-	var otpToken string
+	var user model.User
+	var token model.AuthToken
+
+	// Get stored refresh token
+	stored, err := s.refreshR.GetByTokenHash(dbctx, utils.HashToken(req.RefreshToken))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidRefreshToken
+		}
+		return user, token, err
+	}
+
+	// Refresh token issued to an oauth client is only usable on the oauth token endpoint
+	if stored.ClientID.Valid {
+		return user, token, ErrInvalidRefreshToken
+	}
+
+	// Reuse of a rotated token, revoke the whole family
+	if stored.RevokedDate.Valid {
+		if err := s.sessionS.End(dbctx, stored.Family); err != nil {
+			return user, token, err
+		}
+		return user, token, ErrRefreshTokenReused
+	}
+
+	// Check expired time
+	if time.Now().In(time.UTC).After(stored.ExpiredDate) {
+		return user, token, fmt.Errorf("%s", "refresh token expired")
+	}
+
+	// Rotate, a concurrent rotation is treated as reuse
+	if err := s.refreshR.Revoke(dbctx, stored.ID); err != nil {
+		if err := s.sessionS.End(dbctx, stored.Family); err != nil {
+			return user, token, err
+		}
+		return user, token, ErrRefreshTokenReused
+	}
+
+	// Get user
+	user, err = s.userR.GetByID(dbctx, stored.UserID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidRefreshToken
+		}
+		return user, token, err
+	}
+	if !user.Status {
+		return user, token, fmt.Errorf("%s", "user is not active")
+	}
+
+	// Generate token in the same family
+	token, err = s.issueAuthToken(dbctx, user, stored.Family)
+
+	return user, token, err
+}
+
+func (s *authService) Logout(dbctx db.DBCtx, req requests.LogoutRequest, tokenMetaData *utils.TokenMetaData) error {
+	// End the current session and revoke its refresh token family
+	family := tokenMetaData.Session
+	if len(req.RefreshToken) > 0 {
+		stored, err := s.refreshR.GetByTokenHash(dbctx, utils.HashToken(req.RefreshToken))
+		if err != nil {
+			if err.Error() == pgx.ErrNoRows.Error() {
+				err = ErrInvalidRefreshToken
+			}
+			return err
+		}
+		if stored.UserID != tokenMetaData.ID {
+			return ErrInvalidRefreshToken
+		}
+		family = stored.Family
+	}
+	if len(family) > 0 {
+		if err := s.sessionS.End(dbctx, family); err != nil {
+			return err
+		}
+	}
+
+	// Deny current access token
+	return utils.RevokeToken(tokenMetaData)
+}
 
+func (s *authService) LogoutAll(dbctx db.DBCtx, userID int64, code string) error {
+	// Revoke every session and refresh token of user
+	if err := s.sessionS.EndAll(dbctx, userID); err != nil {
+		return err
+	}
+	if err := s.refreshR.RevokeAllByUserID(dbctx, userID); err != nil {
+		return err
+	}
+
+	// Deny every access and scoped token issued until now
+	return utils.RevokeUserTokens(code, longestScopedTokenTTL())
+}
+
+// longestScopedTokenTTL lifetime of the longest token issued beside the access token
+func longestScopedTokenTTL() time.Duration {
+	var ttl time.Duration
+	for _, minutes := range []time.Duration{model.VERIFY_TOKEN_EXPIRED_TIME, model.PASSWORD_CHANGE_TOKEN_EXPIRED_TIME, model.MFA_TOKEN_EXPIRED_TIME} {
+		if minutes*time.Minute > ttl {
+			ttl = minutes * time.Minute
+		}
+	}
+
+	return ttl
+}
+
+func (s *authService) MFAVerify(dbctx db.DBCtx, req requests.MFAVerifyRequest, device model.Device) (model.User, model.AuthToken, error) {
+	// Define data
+	var user model.User
+	var token model.AuthToken
+
+	// Decode pending mfa token
+	tokenMetaData, err := utils.DecodeScopedJWT(req.MFAToken, utils.JWT_TYPE_MFA)
+	if err != nil {
+		return user, token, err
+	}
+
+	// Get user
+	user, err = s.userR.GetByID(dbctx, tokenMetaData.ID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidCredential
+		}
+		return user, token, err
+	}
+
+	// Validate second factor, the pending mfa token is revoked after too many wrong codes
+	err = s.mfaS.Verify(dbctx, user.ID, req.Code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			failures, failErr := utils.FailToken(tokenMetaData)
+			if failErr != nil {
+				return user, token, failErr
+			}
+			if failures >= model.MFA_TOKEN_MAX_ATTEMPTS {
+				if revokeErr := utils.RevokeToken(tokenMetaData); revokeErr != nil {
+					return user, token, revokeErr
+				}
+			}
+		}
+		return user, token, err
+	}
+
+	// Generate token of a new session, the pending mfa token is revoked by the caller once committed
+	token, err = s.startSession(dbctx, user, device)
+
+	return user, token, err
+}
+
+// IssueLoginToken returns a password change token when the password must be set, an mfa token
+// when a second factor is required, otherwise the access token with a new refresh token family
+func (s *authService) IssueLoginToken(dbctx db.DBCtx, user model.User, device model.Device) (model.AuthToken, error) {
+	var token model.AuthToken
+	var err error
+
+	// Password set by an admin must be changed before issuing the real token
+	if user.MustChangePassword {
+		token.PasswordChangeToken, _, err = utils.GenerateScopedJWT(utils.JWT_TYPE_PASSWORD, user.ID, user.Code, user.Phone, user.Email, user.Role, model.PASSWORD_CHANGE_TOKEN_EXPIRED_TIME*time.Minute)
+		return token, err
+	}
+
+	// Second factor required before issuing the real token
+	mfaEnabled, err := s.mfaS.IsEnabled(dbctx, user.ID)
+	if err != nil {
+		return token, err
+	}
+	if mfaEnabled {
+		token.MFAToken, _, err = utils.GenerateScopedJWT(utils.JWT_TYPE_MFA, user.ID, user.Code, user.Phone, user.Email, user.Role, model.MFA_TOKEN_EXPIRED_TIME*time.Minute)
+		return token, err
+	}
+
+	// Generate token of a new session
+	return s.startSession(dbctx, user, device)
+}
+
+// startSession generate the token with a new refresh token family and record the session of the device
+func (s *authService) startSession(dbctx db.DBCtx, user model.User, device model.Device) (model.AuthToken, error) {
+	family := common.CodeGenerator(model.REFRESH_FAMILY_PREFIX, 16)
+	token, err := s.issueAuthToken(dbctx, user, family)
+	if err != nil {
+		return token, err
+	}
+
+	return token, s.sessionS.Start(dbctx, user.ID, family, device)
+}
+
+// issueAuthToken generate access token and store a new refresh token in family
+func (s *authService) issueAuthToken(dbctx db.DBCtx, user model.User, family string) (model.AuthToken, error) {
+	var token model.AuthToken
+
+	// Generate access token
+	accessToken, accessExpires, err := utils.GenerateJWT(user.ID, user.Code, user.Phone, user.Email, user.Role, family)
+	if err != nil {
+		return token, err
+	}
+
+	// Generate refresh token
+	refreshToken, refreshHash, refreshExpires, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return token, err
+	}
+	_, err = s.refreshR.Insert(dbctx, model.RefreshToken{
+		UserID:      user.ID,
+		TokenHash:   refreshHash,
+		Family:      family,
+		ExpiredDate: refreshExpires,
+	})
+	if err != nil {
+		return token, err
+	}
+
+	token = model.AuthToken{
+		AccessToken:    accessToken,
+		AccessExpires:  accessExpires,
+		RefreshToken:   refreshToken,
+		RefreshExpires: refreshExpires.Unix(),
+	}
+
+	return token, err
+}
+
+func (s *authService) SendOTPTokenByType(dbctx db.DBCtx, emailPhone string, client model.ApiClient, sendType string) (bool, error) {
 	// Check valid emailPhone
-	via, validChannel := model.ViaValidMailPhoneChannel(channel, emailPhone)
+	via, validChannel := model.ViaValidMailPhoneChannel(client.Channel, emailPhone)
 	if !validChannel {
-		return otpToken, false, errors.New("invalid channel email or phone")
+		return false, errors.New("invalid channel email or phone")
 	}
 
 	// Valid type usage
 	err := utils.CheckValidUsedFor(sendType)
 	if err != nil {
-		return otpToken, false, err
+		return false, err
 	}
 
 	// Get user
 	user, err := s.userR.GetByEmailOrPhone(dbctx, emailPhone)
 	if err != nil {
 		if err.Error() == pgx.ErrNoRows.Error() {
-			err = fmt.Errorf(`invalid user credential`)
+			err = ErrInvalidCredential
 		}
-		return otpToken, user.Status, err
+		return user.Status, err
 	}
 
-	// Adjustment login user role channel
-	err = model.CheckValidChannelRole(channel, user.Role)
+	// Adjustment login user role of client
+	err = client.CheckAllowedRole(user.Role)
 	if err != nil {
-		return otpToken, user.Status, err
+		return user.Status, err
 	}
 
 	// Adjustment sendType user not active
@@ -241,48 +516,150 @@ func (s *authService) SendOTPTokenByType(dbctx db.DBCtx, emailPhone, channel, se
 	}
 
 	// Set / get otp user
-	otpToken, err = s.GenerateOTPToken(dbctx, channel, user.Email, user.Phone)
+	otpToken, err := s.GenerateOTPToken(dbctx, client.Channel, sendType, user)
 	if err != nil {
-		return otpToken, user.Status, err
+		return user.Status, err
 	}
 
-	// Send otp to queue mail
-	err = s.SendOTPToken(rabbitCfg, emailPhone, channel, sendType, otpToken, via)
+	// Send otp to queue mail, it is only delivered to the user
+	err = s.SendOTPToken(dbctx, user, client, sendType, otpToken, via)
 
-	return otpToken, user.Status, err
+	return user.Status, err
 }
 
-func (s *authService) ChangePassword(dbctx db.DBCtx, req requests.ResetPasswordRequest, channel string) error {
+func (s *authService) ChangePassword(dbctx db.DBCtx, req requests.ResetPasswordRequest, client model.ApiClient) error {
+	// Compare password
+	if req.Password != req.ConfirmPassword {
+		return fmt.Errorf("%s", "password is not match.")
+	}
+
 	// Adjustment emailPhone channel
+	var userOTP model.UserOTP
 	emailPhone := req.EmailPhoneToken
-	if channel == utils.ChannelWeb {
-		// Decode token
-		tokenDecoded, err := utils.DecodeTokenJWT(emailPhone)
+	if client.Channel == utils.ChannelWeb {
+		// Check reset password token, it is used once the password is changed
+		tokenMetaData, err := utils.VerifyVerificationJWT(emailPhone, utils.MAIL_FOR_RESETPASS)
+		if err != nil {
+			return err
+		}
+		emailPhone = tokenMetaData.Email
+	} else if client.Channel == utils.ChannelApp {
+		// Use reset password otp verified before
+		var err error
+		userOTP, err = s.otpR.GetActiveByEmailOrPhone(dbctx, emailPhone, utils.MAIL_FOR_RESETPASS)
 		if err != nil {
+			if err.Error() == pgx.ErrNoRows.Error() {
+				err = ErrInvalidOTP
+			}
 			return err
 		}
-		emailPhone = tokenDecoded["email"].(string)
+		if !userOTP.VerifiedDate.Valid || time.Now().In(time.UTC).After(userOTP.ExpiredDate) {
+			return ErrInvalidOTP
+		}
+	}
+
+	// Set password
+	user, err := s.userR.GetByEmailOrPhone(dbctx, emailPhone)
+	if err != nil {
+		return err
+	}
+	err = s.passwordS.Set(dbctx, user, req.Password)
+	if err != nil {
+		return err
+	}
+
+	// Use reset password token or otp
+	if client.Channel == utils.ChannelWeb {
+		_, err = utils.ConsumeVerificationJWT(req.EmailPhoneToken, utils.MAIL_FOR_RESETPASS)
+	} else if client.Channel == utils.ChannelApp {
+		err = s.otpR.Consume(dbctx, userOTP.ID)
+	}
+
+	return err
+}
+
+func (s *authService) PasswordChange(dbctx db.DBCtx, req requests.PasswordChangeRequest, device model.Device) (model.User, model.AuthToken, error) {
+	// Define data
+	var user model.User
+	var token model.AuthToken
+
+	// Compare password
+	if req.Password != req.ConfirmPassword {
+		return user, token, fmt.Errorf("%s", "password is not match.")
+	}
+
+	// Decode password change token
+	tokenMetaData, err := utils.DecodeScopedJWT(req.PasswordChangeToken, utils.JWT_TYPE_PASSWORD)
+	if err != nil {
+		return user, token, err
+	}
+
+	// Get user
+	user, err = s.userR.GetByID(dbctx, tokenMetaData.ID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidCredential
+		}
+		return user, token, err
+	}
+	if !user.MustChangePassword {
+		return user, token, ErrPasswordChangeNotRequired
+	}
+
+	// Set password
+	err = s.passwordS.Set(dbctx, user, req.Password)
+	if err != nil {
+		return user, token, err
 	}
+	user.MustChangePassword = false
+
+	// Issue login token, the password change token is revoked by the caller once committed
+	token, err = s.IssueLoginToken(dbctx, user, device)
+
+	return user, token, err
+}
 
+func (s *authService) AcceptInvitation(dbctx db.DBCtx, req requests.AcceptInvitationRequest) error {
 	// Compare password
 	if req.Password != req.ConfirmPassword {
 		return fmt.Errorf("%s", "password is not match.")
 	}
 
-	// Hash password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.MinCost)
+	// Get pending invitation
+	invitation, err := s.invitationR.GetActiveByTokenHash(dbctx, utils.HashToken(req.Token))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidInvitation
+		}
+		return err
+	}
+	user, err := s.userR.GetByID(dbctx, invitation.UserID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidInvitation
+		}
+		return err
+	}
+
+	// Set password and use invitation
+	err = s.passwordS.Set(dbctx, user, req.Password)
+	if err != nil {
+		return err
+	}
+	err = s.invitationR.Accept(dbctx, invitation.ID)
 	if err != nil {
 		return err
 	}
-	newPassword := string(passwordHash)
 
-	// Update password
-	err = s.userR.UpdatePasswordByEmailOrPhone(dbctx, newPassword, emailPhone)
+	// Invitation link sent to the email activates the user
+	if !user.Status {
+		err = s.userR.UpdateStatusByEmailOrPhone(dbctx, true, user.Email)
+	}
 
 	return err
 }
 
-func (s *authService) OTPTokenValidation(dbctx db.DBCtx, req requests.ValidateOTPTokenRequest, channel, sendType string) error {
+func (s *authService) OTPTokenValidation(dbctx db.DBCtx, req requests.ValidateOTPTokenRequest, client model.ApiClient, sendType string) error {
 	// Valid type usage
 	err := utils.CheckValidUsedFor(sendType)
 	if err != nil {
@@ -290,37 +667,41 @@ func (s *authService) OTPTokenValidation(dbctx db.DBCtx, req requests.ValidateOT
 	}
 
 	// Check valid emailPhone
-	_, validChannel := model.ViaValidMailPhoneChannel(channel, req.EmailPhone)
+	_, validChannel := model.ViaValidMailPhoneChannel(client.Channel, req.EmailPhone)
 	if !validChannel {
 		return errors.New("invalid channel email or phone")
 	}
 
-	if channel == utils.ChannelWeb {
-		// Decode token
-		tokenDecoded, err := utils.DecodeTokenJWT(req.OTPToken)
+	if client.Channel == utils.ChannelWeb {
+		// Reset password token is used when the password is changed, others are used now
+		var tokenMetaData *utils.TokenMetaData
+		if sendType == utils.MAIL_FOR_RESETPASS {
+			tokenMetaData, err = utils.VerifyVerificationJWT(req.OTPToken, sendType)
+		} else {
+			tokenMetaData, err = utils.ConsumeVerificationJWT(req.OTPToken, sendType)
+		}
 		if err != nil {
 			return err
 		}
 
 		// Check decode token with email
-		if tokenDecoded["email"].(string) != req.EmailPhone {
+		if tokenMetaData.Email != req.EmailPhone {
 			return errors.New("invalid token email")
 		}
-	} else if channel == utils.ChannelApp {
-		// Get user otp
-		userOTP, err := s.otpR.GetByEmailOrPhone(dbctx, req.EmailPhone, req.EmailPhone)
+	} else if client.Channel == utils.ChannelApp {
+		userOTP, err := s.checkOTP(dbctx, req.EmailPhone, sendType, req.OTPToken)
 		if err != nil {
 			return err
 		}
 
-		// Check expired time
-		if time.Now().In(time.UTC).After(userOTP.ExpiredDate) {
-			return fmt.Errorf("%s", "otp expired")
+		// Reset password otp is used when the password is changed, others are used now
+		if sendType == utils.MAIL_FOR_RESETPASS {
+			err = s.otpR.Verify(dbctx, userOTP.ID)
+		} else {
+			err = s.otpR.Consume(dbctx, userOTP.ID)
 		}
-
-		// Check contains equal otp
-		if strings.Trim(userOTP.OTP, " ") != strings.Trim(req.OTPToken, " ") {
-			return fmt.Errorf("%s", "invalid otp")
+		if err != nil {
+			return err
 		}
 	}
 
@@ -331,3 +712,119 @@ func (s *authService) OTPTokenValidation(dbctx db.DBCtx, req requests.ValidateOT
 
 	return err
 }
+
+// ConsumeOTPToken use the otp, or the link token for channel web, generated for the usage,
+// returns the user data the token was generated for
+func (s *authService) ConsumeOTPToken(dbctx db.DBCtx, channel, usedFor, emailPhone, otpToken string) (model.User, error) {
+	if channel == utils.ChannelWeb {
+		tokenMetaData, err := utils.ConsumeVerificationJWT(otpToken, usedFor)
+		if err != nil {
+			return model.User{}, err
+		}
+		return model.User{ID: tokenMetaData.ID, Code: tokenMetaData.Code, Email: tokenMetaData.Email, Phone: tokenMetaData.Phone}, nil
+	}
+
+	userOTP, err := s.checkOTP(dbctx, emailPhone, usedFor, otpToken)
+	if err != nil {
+		return model.User{}, err
+	}
+	err = s.otpR.Consume(dbctx, userOTP.ID)
+
+	return model.User{Email: userOTP.Email, Phone: userOTP.Phone}, err
+}
+
+func (s *authService) PasswordlessLogin(dbctx db.DBCtx, req requests.ValidateOTPTokenRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error) {
+	// Define data
+	var user model.User
+	var token model.AuthToken
+
+	// Check valid emailPhone
+	_, validChannel := model.ViaValidMailPhoneChannel(client.Channel, req.EmailPhone)
+	if !validChannel {
+		return user, token, errors.New("invalid channel email or phone")
+	}
+
+	if client.Channel == utils.ChannelWeb {
+		// Use login link token
+		tokenMetaData, err := utils.ConsumeVerificationJWT(req.OTPToken, utils.MAIL_FOR_LOGIN)
+		if err != nil {
+			return user, token, err
+		}
+		if tokenMetaData.Email != req.EmailPhone {
+			return user, token, ErrInvalidOTP
+		}
+	} else if client.Channel == utils.ChannelApp {
+		userOTP, err := s.checkOTP(dbctx, req.EmailPhone, utils.MAIL_FOR_LOGIN, req.OTPToken)
+		if err != nil {
+			return user, token, err
+		}
+
+		// Login otp can only be used once
+		err = s.otpR.Consume(dbctx, userOTP.ID)
+		if err != nil {
+			return user, token, err
+		}
+	}
+
+	// Get user
+	user, err := s.userR.GetByEmailOrPhone(dbctx, req.EmailPhone)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidCredential
+		}
+		return user, token, err
+	}
+	if !user.Status {
+		return user, token, fmt.Errorf("%s", "user is not active")
+	}
+
+	// Adjustment login user role of client
+	err = client.CheckAllowedRole(user.Role)
+	if err != nil {
+		return user, token, err
+	}
+
+	token, err = s.IssueLoginToken(dbctx, user, device)
+	if err != nil {
+		return user, token, err
+	}
+	if len(token.AccessToken) > 0 {
+		user.RememberToken = sql.NullString{Valid: true, String: token.AccessToken}
+	}
+
+	return user, token, err
+}
+
+// checkOTP find the active otp of email or phone for the purpose and check it is the given otp,
+// every check uses one of its attempts before comparing, so parallel guesses can not exceed them
+func (s *authService) checkOTP(dbctx db.DBCtx, emailPhone, purpose, otp string) (model.UserOTP, error) {
+	// Get and lock user otp
+	userOTP, err := s.otpR.GetActiveForUpdate(dbctx, emailPhone, purpose)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidOTP
+		}
+		return userOTP, err
+	}
+
+	// Check expired time
+	if time.Now().In(time.UTC).After(userOTP.ExpiredDate) {
+		return userOTP, fmt.Errorf("%s", "otp expired")
+	}
+
+	// Take the attempt, none left when no row is updated
+	userOTP.AttemptsLeft, err = s.otpR.DecrementAttempts(dbctx, userOTP.ID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrOTPAttemptsExceeded
+		}
+		return userOTP, err
+	}
+
+	// Check contains equal otp
+	if !userOTP.CheckOTP(strings.Trim(otp, " ")) {
+		return userOTP, ErrInvalidOTP
+	}
+
+	return userOTP, nil
+}
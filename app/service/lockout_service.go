@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	lockoutAttemptPrefix = "auth:attempt:"
+	lockoutLockPrefix    = "auth:lock:"
+	lockoutScopeAccount  = "account:"
+	lockoutScopeIP       = "ip:"
+)
+
+// LockoutError returned while an account or ip is temporarily locked
+type LockoutError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("too many attempts, please retry after %d seconds", int(math.Ceil(e.RetryAfter.Seconds())))
+}
+
+type LockoutService interface {
+	Check(ctx context.Context, account, ip string) error
+	Fail(dbctx db.DBCtx, account, ip string) error
+	Reset(ctx context.Context, account string) error
+	Unlock(dbctx db.DBCtx, accounts []string, handlerBy string) error
+}
+
+type lockoutService struct {
+	auditR repository.AuthAuditRepository
+	redis  *config.Redis
+	cfg    config.LockoutConfig
+}
+
+func NewLockoutService(audit repository.AuthAuditRepository, redis *config.Redis, cfg config.LockoutConfig) *lockoutService {
+	return &lockoutService{audit, redis, cfg}
+}
+
+func (s *lockoutService) Check(ctx context.Context, account, ip string) error {
+	var retryAfter time.Duration
+	for _, key := range s.keys(account, ip) {
+		ttl, err := s.redis.RedisDefault.TTL(ctx, lockoutLockPrefix+key).Result()
+		if err != nil {
+			return err
+		}
+		if ttl > retryAfter {
+			retryAfter = ttl
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockoutError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (s *lockoutService) Fail(dbctx db.DBCtx, account, ip string) error {
+	var retryAfter time.Duration
+	for _, key := range s.keys(account, ip) {
+		// Count failed attempt in window
+		attempts, err := s.redis.RedisDefault.Incr(dbctx.Ctx, lockoutAttemptPrefix+key).Result()
+		if err != nil {
+			return err
+		}
+		if attempts == 1 {
+			if err := s.redis.RedisDefault.Expire(dbctx.Ctx, lockoutAttemptPrefix+key, s.cfg.AttemptWindow).Err(); err != nil {
+				return err
+			}
+		}
+
+		maxAttempts := s.cfg.MaxAccountAttempts
+		if strings.HasPrefix(key, lockoutScopeIP) {
+			maxAttempts = s.cfg.MaxIPAttempts
+		}
+		if attempts < int64(maxAttempts) {
+			continue
+		}
+
+		// Exponential backoff, doubled for every attempt over the limit
+		lockDuration := s.cfg.LockoutBase * time.Duration(math.Pow(2, math.Min(float64(attempts-int64(maxAttempts)), 20)))
+		if lockDuration > s.cfg.LockoutMax {
+			lockDuration = s.cfg.LockoutMax
+		}
+		if err := s.redis.RedisDefault.Set(dbctx.Ctx, lockoutLockPrefix+key, attempts, lockDuration).Err(); err != nil {
+			return err
+		}
+		if lockDuration > retryAfter {
+			retryAfter = lockDuration
+		}
+
+		// Audit lockout
+		err = s.auditR.Insert(dbctx, model.AuthAudit{
+			Event:      model.AUDIT_EVENT_LOCKOUT,
+			Identifier: key,
+			IP:         ip,
+			Detail:     sql.NullString{Valid: true, String: fmt.Sprintf("%d failed attempts, locked for %s", attempts, lockDuration)},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if retryAfter > 0 {
+		return &LockoutError{RetryAfter: retryAfter}
+	}
+
+	return nil
+}
+
+func (s *lockoutService) Reset(ctx context.Context, account string) error {
+	key := lockoutScopeAccount + strings.ToLower(strings.TrimSpace(account))
+
+	return s.redis.RedisDefault.Del(ctx, lockoutAttemptPrefix+key).Err()
+}
+
+func (s *lockoutService) Unlock(dbctx db.DBCtx, accounts []string, handlerBy string) error {
+	for _, account := range accounts {
+		key := lockoutScopeAccount + strings.ToLower(strings.TrimSpace(account))
+		if err := s.redis.RedisDefault.Del(dbctx.Ctx, lockoutAttemptPrefix+key, lockoutLockPrefix+key).Err(); err != nil {
+			return err
+		}
+
+		// Audit unlock
+		err := s.auditR.Insert(dbctx, model.AuthAudit{
+			Event:      model.AUDIT_EVENT_UNLOCK,
+			Identifier: key,
+			IP:         "",
+			CreatedBy:  sql.NullString{Valid: true, String: handlerBy},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// keys of the counters checked for an attempt, account is optional
+func (s *lockoutService) keys(account, ip string) []string {
+	var keys []string
+	if account = strings.ToLower(strings.TrimSpace(account)); len(account) > 0 {
+		keys = append(keys, lockoutScopeAccount+account)
+	}
+	keys = append(keys, lockoutScopeIP+ip)
+
+	return keys
+}
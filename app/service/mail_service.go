@@ -5,7 +5,7 @@ import (
 )
 
 type MailService interface {
-	MailSender(receivers []string, usage string, data interface{}) error
+	MailSender(receivers []string, usage string, data interface{}, attachments ...utils.MailAttachment) error
 }
 
 type mailService struct {
@@ -15,13 +15,13 @@ func NewMailService() *mailService {
 	return &mailService{}
 }
 
-func (s *mailService) MailSender(receivers []string, usage string, data interface{}) error {
+func (s *mailService) MailSender(receivers []string, usage string, data interface{}, attachments ...utils.MailAttachment) error {
 	emailReq, err := utils.NewRequestSendMail(receivers, usage)
 	if err != nil {
 		return err
 	}
 
-	emailReq.Send(usage, data)
+	_, err = emailReq.Send(usage, data, attachments...)
 
 	return err
 }
@@ -0,0 +1,203 @@
+package service
+
+import (
+	"errors"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	mfaKeyInfo              = "user-mfa-secret"
+	mfaRecoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+var ErrInvalidMFACode = errors.New("invalid mfa code")
+
+type MFAService interface {
+	Enroll(dbctx db.DBCtx, userID int64, account string) (string, string, error)
+	Enable(dbctx db.DBCtx, userID int64, code string) ([]string, error)
+	Disable(dbctx db.DBCtx, userID int64, code string) error
+	IsEnabled(dbctx db.DBCtx, userID int64) (bool, error)
+	Verify(dbctx db.DBCtx, userID int64, code string) error
+}
+
+type mfaService struct {
+	mfaR repository.UserMFARepository
+	cfg  config.MFAConfig
+}
+
+func NewMFAService(mfa repository.UserMFARepository, cfg config.MFAConfig) *mfaService {
+	return &mfaService{mfa, cfg}
+}
+
+func (s *mfaService) Enroll(dbctx db.DBCtx, userID int64, account string) (string, string, error) {
+	// Check enabled mfa
+	enabled, err := s.IsEnabled(dbctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	if enabled {
+		return "", "", errors.New("mfa already enabled")
+	}
+
+	// Generate secret
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Store pending enrolment
+	_, err = s.mfaR.Upsert(dbctx, model.UserMFA{
+		UserID:        userID,
+		Secret:        encrypted,
+		RecoveryCodes: []string{},
+		Status:        false,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, utils.TOTPProvisioningURI(s.cfg.Issuer, account, secret), err
+}
+
+func (s *mfaService) Enable(dbctx db.DBCtx, userID int64, code string) ([]string, error) {
+	// Get pending enrolment
+	userMFA, err := s.mfaR.GetByUserID(dbctx, userID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = errors.New("mfa is not enrolled")
+		}
+		return nil, err
+	}
+	if userMFA.Status {
+		return nil, errors.New("mfa already enabled")
+	}
+
+	// Validate code
+	step, err := s.validateTOTP(userMFA, code)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate recovery codes
+	var codes, hashes []string
+	for i := 0; i < model.MFA_RECOVERY_CODES; i++ {
+		random, err := utils.RandomString(10, mfaRecoveryCodeAlphabet)
+		if err != nil {
+			return nil, err
+		}
+		recoveryCode := fmt.Sprintf("%s-%s", random[:5], random[5:])
+		codes = append(codes, recoveryCode)
+		hashes = append(hashes, utils.HashToken(recoveryCode))
+	}
+
+	// Enable mfa
+	userMFA.RecoveryCodes = hashes
+	userMFA.LastUsedStep = step
+	userMFA.Status = true
+	err = s.mfaR.Update(dbctx, userMFA)
+
+	return codes, err
+}
+
+func (s *mfaService) Disable(dbctx db.DBCtx, userID int64, code string) error {
+	// Validate code
+	if err := s.Verify(dbctx, userID, code); err != nil {
+		return err
+	}
+
+	return s.mfaR.DeleteByUserID(dbctx, userID)
+}
+
+func (s *mfaService) IsEnabled(dbctx db.DBCtx, userID int64) (bool, error) {
+	userMFA, err := s.mfaR.GetByUserID(dbctx, userID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return userMFA.Status, nil
+}
+
+func (s *mfaService) Verify(dbctx db.DBCtx, userID int64, code string) error {
+	// Get enabled mfa, locked so a code or recovery code is only used once
+	userMFA, err := s.mfaR.GetByUserIDForUpdate(dbctx, userID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = errors.New("mfa is not enabled")
+		}
+		return err
+	}
+	if !userMFA.Status {
+		return errors.New("mfa is not enabled")
+	}
+
+	// Validate authenticator code
+	code = strings.ToLower(strings.TrimSpace(code))
+	if len(code) == utils.TOTP_DIGITS {
+		step, err := s.validateTOTP(userMFA, code)
+		if err != nil {
+			return err
+		}
+		userMFA.LastUsedStep = step
+		return s.mfaR.Update(dbctx, userMFA)
+	}
+
+	// Validate recovery code, each code can only be used once
+	hash := utils.HashToken(code)
+	for i, recoveryCode := range userMFA.RecoveryCodes {
+		if recoveryCode == hash {
+			userMFA.RecoveryCodes = append(userMFA.RecoveryCodes[:i], userMFA.RecoveryCodes[i+1:]...)
+			return s.mfaR.Update(dbctx, userMFA)
+		}
+	}
+
+	return ErrInvalidMFACode
+}
+
+// validateTOTP check code against secret, a code already used is rejected
+func (s *mfaService) validateTOTP(userMFA model.UserMFA, code string) (int64, error) {
+	secret, err := s.decrypt(userMFA.Secret)
+	if err != nil {
+		return 0, err
+	}
+
+	step, valid := utils.ValidateTOTP(secret, code, time.Now())
+	if !valid || step <= userMFA.LastUsedStep {
+		return 0, ErrInvalidMFACode
+	}
+
+	return step, nil
+}
+
+func (s *mfaService) encrypt(secret string) (string, error) {
+	key, err := utils.DeriveKey(s.cfg.SecretKey, mfaKeyInfo)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.EncryptString(key, secret)
+}
+
+func (s *mfaService) decrypt(secret string) (string, error) {
+	key, err := utils.DeriveKey(s.cfg.SecretKey, mfaKeyInfo)
+	if err != nil {
+		return "", err
+	}
+
+	return utils.DecryptString(key, secret)
+}
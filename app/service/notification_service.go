@@ -0,0 +1,416 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"sort"
+	"text/template"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type NotificationService interface {
+	Notify(dbctx db.DBCtx, notificationType string, user model.User, data interface{}, channels ...string) error
+	NotifyWithAttachments(dbctx db.DBCtx, notificationType string, user model.User, data interface{}, attachments []utils.MailAttachment, channels ...string) error
+	Dispatch(dbctx db.DBCtx, rabbitCfg *config.RabbitMQ, limit int) (int, error)
+	Deliver(dbctx db.DBCtx, data utils.NotificationData) error
+	FindInbox(dbctx db.DBCtx, userID int64, c *fiber.Ctx) ([]model.Notification, int64, common.PaginateQueryOffset, error)
+	MarkRead(dbctx db.DBCtx, userID, id int64) error
+	MarkAllRead(dbctx db.DBCtx, userID int64) error
+	FindPreferences(dbctx db.DBCtx, userID int64) ([]model.NotificationPreference, error)
+	UpdatePreferences(dbctx db.DBCtx, userID int64, req requests.NotificationPreferenceRequest) ([]model.NotificationPreference, error)
+}
+
+var (
+	ErrUnknownNotificationType = errors.New("unknown notification type")
+	ErrNotificationNotFound    = errors.New("notification not found")
+)
+
+type notificationService struct {
+	notificationR repository.NotificationRepository
+	deliveryR     repository.NotificationDeliveryRepository
+	preferenceR   repository.NotificationPreferenceRepository
+	mailS         MailService
+	cfg           config.NotificationConfig
+}
+
+func NewNotificationService(notification repository.NotificationRepository, delivery repository.NotificationDeliveryRepository, preference repository.NotificationPreferenceRepository, mail MailService, cfg config.NotificationConfig) *notificationService {
+	return &notificationService{notification, delivery, preference, mail, cfg}
+}
+
+// Notify record the notification of the user and a pending delivery to every channel, in the transaction of
+// the caller, the email and phone of the user are the addresses it is sent to, channels restrict the channels
+// of the type. Deliveries are queued by Dispatch once committed, nothing is sent when the caller rolls back
+func (s *notificationService) Notify(dbctx db.DBCtx, notificationType string, user model.User, data interface{}, channels ...string) error {
+	return s.NotifyWithAttachments(dbctx, notificationType, user, data, nil, channels...)
+}
+
+// NotifyWithAttachments notify like Notify, the attachments are sent with the email, by reference when they
+// have a path readable by the queue consumer, otherwise their content is carried in the queue payload
+func (s *notificationService) NotifyWithAttachments(dbctx db.DBCtx, notificationType string, user model.User, data interface{}, attachments []utils.MailAttachment, channels ...string) error {
+	nt, ok := model.NotificationTypes[notificationType]
+	if !ok {
+		return ErrUnknownNotificationType
+	}
+
+	// Resolve channels
+	channels, err := s.channels(dbctx, notificationType, nt, user, channels)
+	if err != nil || len(channels) <= 0 {
+		return err
+	}
+
+	message, err := renderMessage(notificationType, nt, data)
+	if err != nil {
+		return err
+	}
+
+	// Record notification, the message of security notifications is not stored
+	notification := model.Notification{
+		UserID: user.ID,
+		Type:   notificationType,
+		Title:  nt.Title,
+	}
+	if !nt.Security {
+		notification.Body = message
+		notification.Inbox = inChannels(model.NOTIFY_CHANNEL_INAPP, channels)
+	}
+	notification, err = s.notificationR.Insert(dbctx, notification)
+	if err != nil {
+		return err
+	}
+
+	// Record delivery of every channel with its queue payload, the inbox is the notification itself
+	for _, channel := range channels {
+		var recipient string
+		switch channel {
+		case model.NOTIFY_CHANNEL_EMAIL:
+			recipient = user.Email
+		case model.NOTIFY_CHANNEL_SMS:
+			recipient = user.Phone
+		case model.NOTIFY_CHANNEL_WEBHOOK:
+			recipient = s.cfg.WebhookURL
+		default:
+			continue
+		}
+
+		queueData := utils.NotificationData{
+			Channel:   channel,
+			Recipient: recipient,
+			Type:      notificationType,
+			Title:     nt.Title,
+			Message:   message,
+			Data:      data,
+		}
+		if channel == model.NOTIFY_CHANNEL_EMAIL {
+			queueData.Attachments = attachments
+		}
+		if channel == model.NOTIFY_CHANNEL_WEBHOOK {
+			queueData.Data = map[string]interface{}{
+				"id":           notification.ID,
+				"type":         notificationType,
+				"user_code":    user.Code,
+				"title":        nt.Title,
+				"message":      message,
+				"created_date": notification.CreatedDate,
+			}
+		}
+		payload, err := json.Marshal(queueData)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.deliveryR.Insert(dbctx, model.NotificationDelivery{
+			NotificationID: notification.ID,
+			Channel:        channel,
+			Recipient:      recipient,
+			Status:         model.DELIVERY_PENDING,
+			Payload:        payload,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Dispatch queue the committed pending deliveries, up to limit, answers how many were queued.
+// It runs in its own transaction, a delivery queued but not marked is queued again
+func (s *notificationService) Dispatch(dbctx db.DBCtx, rabbitCfg *config.RabbitMQ, limit int) (int, error) {
+	list, err := s.deliveryR.GetPending(dbctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, delivery := range list {
+		var queueData utils.NotificationData
+		if err = json.Unmarshal(delivery.Payload, &queueData); err != nil {
+			return i, err
+		}
+		queueData.DeliveryID = delivery.ID
+
+		if err = utils.PushQueueNotification(rabbitCfg, utils.CMDQueueSendNotification, queueData); err != nil {
+			return i, err
+		}
+		if err = s.deliveryR.MarkQueued(dbctx, delivery.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(list), nil
+}
+
+// Deliver send a queued delivery through its channel and record the result, deliveries without
+// a record (queued before notifications) only carry the data their message is rendered from
+func (s *notificationService) Deliver(dbctx db.DBCtx, data utils.NotificationData) error {
+	var err error
+	if data.Channel == model.NOTIFY_CHANNEL_SMS && len(data.Message) <= 0 {
+		nt, ok := model.NotificationTypes[data.Type]
+		if !ok {
+			return ErrUnknownNotificationType
+		}
+		if data.Message, err = renderMessage(data.Type, nt, data.Data); err != nil {
+			return err
+		}
+	}
+
+	switch data.Channel {
+	case model.NOTIFY_CHANNEL_EMAIL:
+		err = s.mailS.MailSender([]string{data.Recipient}, data.Type, data.Data, data.Attachments...)
+	case model.NOTIFY_CHANNEL_SMS:
+		err = utils.SendPhoneText(dbctx.Ctx, data.Recipient, data.Message)
+	case model.NOTIFY_CHANNEL_WEBHOOK:
+		var payload []byte
+		payload, err = json.Marshal(data.Data)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(dbctx.Ctx, s.cfg.WebhookTimeout)
+			err = utils.SendWebhook(ctx, data.Recipient, s.cfg.WebhookSecret, payload)
+			cancel()
+		}
+	default:
+		err = fmt.Errorf(`notification channel %s is not supported`, data.Channel)
+	}
+
+	if data.DeliveryID <= 0 {
+		return err
+	}
+	status := model.DELIVERY_SENT
+	if err != nil {
+		status = model.DELIVERY_FAILED
+	}
+	if updateErr := s.deliveryR.UpdateStatus(dbctx, data.DeliveryID, status, err); updateErr != nil && err == nil {
+		err = updateErr
+	}
+
+	return err
+}
+
+func (s *notificationService) FindInbox(dbctx db.DBCtx, userID int64, c *fiber.Ctx) ([]model.Notification, int64, common.PaginateQueryOffset, error) {
+	var list []model.Notification
+	var total int64
+
+	// Newest first, the order is not taken from the request
+	pg := common.PaginateQueryOffset{Order: common.ParamOrder{Field: "created_date", By: "desc"}}
+	offset, err := common.GetIntParam(c, "offset")
+	if err != nil {
+		return list, total, pg, err
+	}
+	limit, err := common.GetIntParam(c, "limit")
+	if err != nil {
+		return list, total, pg, err
+	}
+	if limit <= 0 || limit > 100 {
+		limit = model.NOTIFICATION_PAGE_LIMIT
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	pg.Offset, pg.Limit = offset, limit
+
+	unread, err := common.GetBoolParam(c, "unread")
+	if err != nil {
+		return list, total, pg, err
+	}
+
+	list, err = s.notificationR.GetInbox(dbctx, userID, unread, pg)
+	if err != nil {
+		return list, total, pg, err
+	}
+	total, err = s.notificationR.GetInboxTotal(dbctx, userID, unread)
+
+	return list, total, pg, err
+}
+
+func (s *notificationService) MarkRead(dbctx db.DBCtx, userID, id int64) error {
+	affected, err := s.notificationR.MarkRead(dbctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if affected <= 0 {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(dbctx db.DBCtx, userID int64) error {
+	return s.notificationR.MarkAllRead(dbctx, userID)
+}
+
+// FindPreferences list every channel of the types users choose, with the default when not set
+func (s *notificationService) FindPreferences(dbctx db.DBCtx, userID int64) ([]model.NotificationPreference, error) {
+	var list []model.NotificationPreference
+
+	saved, err := s.preferencesByKey(dbctx, userID)
+	if err != nil {
+		return list, err
+	}
+
+	var types []string
+	for notificationType := range model.NotificationTypes {
+		types = append(types, notificationType)
+	}
+	sort.Strings(types)
+
+	for _, notificationType := range types {
+		nt := model.NotificationTypes[notificationType]
+		if nt.Security {
+			continue
+		}
+		for _, channel := range nt.Channels {
+			preference, ok := saved[notificationType+":"+channel]
+			if !ok {
+				preference = model.NotificationPreference{
+					UserID:  userID,
+					Type:    notificationType,
+					Channel: channel,
+					Enabled: inChannels(channel, nt.Defaults),
+				}
+			}
+			list = append(list, preference)
+		}
+	}
+
+	return list, nil
+}
+
+func (s *notificationService) UpdatePreferences(dbctx db.DBCtx, userID int64, req requests.NotificationPreferenceRequest) ([]model.NotificationPreference, error) {
+	for _, item := range req.Preferences {
+		nt, ok := model.NotificationTypes[item.Type]
+		if !ok || nt.Security {
+			return nil, fmt.Errorf(`notification type %s can not be changed`, item.Type)
+		}
+		if !inChannels(item.Channel, nt.Channels) {
+			return nil, fmt.Errorf(`channel %s is not available for %s`, item.Channel, item.Type)
+		}
+
+		err := s.preferenceR.Upsert(dbctx, model.NotificationPreference{
+			UserID:  userID,
+			Type:    item.Type,
+			Channel: item.Channel,
+			Enabled: *item.Enabled,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return s.FindPreferences(dbctx, userID)
+}
+
+// channels resolve the channels of the notification, the preferences of the user choose them
+// unless the notification is a security one or the caller restricts them
+func (s *notificationService) channels(dbctx db.DBCtx, notificationType string, nt model.NotificationType, user model.User, only []string) ([]string, error) {
+	var candidates []string
+	switch {
+	case len(only) > 0:
+		for _, channel := range only {
+			if inChannels(channel, nt.Channels) {
+				candidates = append(candidates, channel)
+			}
+		}
+	case nt.Security:
+		candidates = nt.Channels
+	default:
+		saved, err := s.preferencesByKey(dbctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, channel := range nt.Channels {
+			enabled := inChannels(channel, nt.Defaults)
+			if preference, ok := saved[notificationType+":"+channel]; ok {
+				enabled = preference.Enabled
+			}
+			if enabled {
+				candidates = append(candidates, channel)
+			}
+		}
+	}
+
+	// Drop channels without an address
+	var channels []string
+	for _, channel := range candidates {
+		if (channel == model.NOTIFY_CHANNEL_EMAIL && len(user.Email) <= 0) ||
+			(channel == model.NOTIFY_CHANNEL_SMS && len(user.Phone) <= 0) ||
+			(channel == model.NOTIFY_CHANNEL_WEBHOOK && len(s.cfg.WebhookURL) <= 0) {
+			continue
+		}
+		channels = append(channels, channel)
+	}
+
+	return channels, nil
+}
+
+// preferencesByKey get the saved preferences of the user by type:channel
+func (s *notificationService) preferencesByKey(dbctx db.DBCtx, userID int64) (map[string]model.NotificationPreference, error) {
+	saved := map[string]model.NotificationPreference{}
+	preferences, err := s.preferenceR.GetByUserID(dbctx, userID)
+	if err != nil {
+		return saved, err
+	}
+	for _, preference := range preferences {
+		saved[preference.Type+":"+preference.Channel] = preference
+	}
+
+	return saved, nil
+}
+
+// renderMessage render the message template of the notification type with its data
+func renderMessage(notificationType string, nt model.NotificationType, data interface{}) (string, error) {
+	t, err := template.New(notificationType).Parse(nt.Message)
+	if err != nil {
+		return "", err
+	}
+	buffer := new(bytes.Buffer)
+	if err = t.Execute(buffer, data); err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func inChannels(channel string, channels []string) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// noticeDate format the date shown in the account notices
+func noticeDate(t time.Time) string {
+	return t.In(time.UTC).Format("02 Jan 2006 15:04 MST")
+}
@@ -0,0 +1,443 @@
+package service
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	oauthCodePrefix   = "oauth:code:"
+	oauthCodeAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+type OAuthService interface {
+	Consent(dbctx db.DBCtx, req requests.OAuthAuthorizeRequest, tokenMetaData *utils.TokenMetaData) (model.ApiClient, []string, error)
+	Approve(dbctx db.DBCtx, req requests.OAuthAuthorizeRequest, tokenMetaData *utils.TokenMetaData) (string, error)
+	Token(dbctx db.DBCtx, req requests.OAuthTokenRequest) (model.AuthToken, []string, error)
+	Introspect(dbctx db.DBCtx, req requests.OAuthTokenActionRequest) (*utils.TokenMetaData, string, error)
+	Revoke(dbctx db.DBCtx, req requests.OAuthTokenActionRequest) error
+}
+
+type oauthService struct {
+	clientR     repository.ApiClientRepository
+	userR       repository.UserRepository
+	refreshR    repository.RefreshTokenRepository
+	clientS     ApiClientService
+	permissionS PermissionService
+	redis       *config.Redis
+	jwtCfg      config.JWTConfig
+}
+
+func NewOAuthService(client repository.ApiClientRepository, user repository.UserRepository, refresh repository.RefreshTokenRepository, clientS ApiClientService, permission PermissionService, redis *config.Redis, jwtCfg config.JWTConfig) *oauthService {
+	return &oauthService{client, user, refresh, clientS, permission, redis, jwtCfg}
+}
+
+func (s *oauthService) Consent(dbctx db.DBCtx, req requests.OAuthAuthorizeRequest, tokenMetaData *utils.TokenMetaData) (model.ApiClient, []string, error) {
+	// Find client of the redirect uri
+	client, err := s.clientR.GetByClientID(dbctx, req.ClientID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = model.NewOAuthError("invalid_client", "unknown client")
+		}
+		return client, nil, err
+	}
+	if err := client.CheckCallbackURL(req.RedirectURI); err != nil {
+		return client, nil, model.NewOAuthError("invalid_request", err.Error())
+	}
+
+	// User role must be allowed for the client
+	user, err := s.userR.GetByID(dbctx, tokenMetaData.ID)
+	if err != nil {
+		return client, nil, err
+	}
+	if err := client.CheckAllowedRole(user.Role); err != nil {
+		return client, nil, model.NewOAuthError("access_denied", err.Error())
+	}
+
+	// Grant only scopes the user role holds
+	scopes, err := s.grantScopes(dbctx, req.Scope, client.Scopes, user.Role)
+
+	return client, scopes, err
+}
+
+func (s *oauthService) Approve(dbctx db.DBCtx, req requests.OAuthAuthorizeRequest, tokenMetaData *utils.TokenMetaData) (string, error) {
+	client, scopes, err := s.Consent(dbctx, req, tokenMetaData)
+	if err != nil {
+		return "", err
+	}
+
+	// Challenge without method is plain, see RFC 7636 section 4.3
+	method := req.CodeChallengeMethod
+	if len(req.CodeChallenge) > 0 && len(method) <= 0 {
+		method = "plain"
+	}
+
+	// Store code, a code is usable once
+	code, err := utils.RandomString(43, oauthCodeAlphabet)
+	if err != nil {
+		return "", err
+	}
+	err = setJSON(dbctx.Ctx, s.redis.RedisDefault, oauthCodePrefix+utils.HashToken(code), model.OAuthCode{
+		ClientID:            client.ClientID,
+		UserID:              tokenMetaData.ID,
+		RedirectURI:         req.RedirectURI,
+		Scopes:              scopes,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: method,
+	}, model.OAUTH_CODE_EXPIRED_TIME*time.Minute)
+	if err != nil {
+		return "", err
+	}
+
+	// Append code and state to the redirect uri
+	redirectURI, err := url.Parse(req.RedirectURI)
+	if err != nil {
+		return "", model.NewOAuthError("invalid_request", "invalid redirect uri")
+	}
+	query := redirectURI.Query()
+	query.Set("code", code)
+	if len(req.State) > 0 {
+		query.Set("state", req.State)
+	}
+	redirectURI.RawQuery = query.Encode()
+
+	return redirectURI.String(), nil
+}
+
+func (s *oauthService) Token(dbctx db.DBCtx, req requests.OAuthTokenRequest) (model.AuthToken, []string, error) {
+	// Authenticate client
+	client, err := s.authenticate(dbctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return model.AuthToken{}, nil, err
+	}
+
+	switch req.GrantType {
+	case model.OAUTH_GRANT_AUTHORIZATION_CODE:
+		return s.exchangeCode(dbctx, req, client)
+	case model.OAUTH_GRANT_CLIENT_CREDENTIALS:
+		return s.clientCredentials(req, client)
+	case model.OAUTH_GRANT_REFRESH_TOKEN:
+		return s.refresh(dbctx, req, client)
+	}
+
+	return model.AuthToken{}, nil, model.NewOAuthError("unsupported_grant_type", "grant type is not supported")
+}
+
+func (s *oauthService) Introspect(dbctx db.DBCtx, req requests.OAuthTokenActionRequest) (*utils.TokenMetaData, string, error) {
+	// Authenticate client
+	_, err := s.authenticate(dbctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Access token
+	if req.TokenTypeHint != model.OAUTH_TOKEN_HINT_REFRESH {
+		if tokenMetaData, err := utils.DecodeScopedJWT(req.Token, utils.JWT_TYPE_OAUTH); err == nil && tokenMetaData != nil {
+			return tokenMetaData, model.OAUTH_TOKEN_HINT_ACCESS, nil
+		}
+	}
+
+	// Refresh token
+	stored, err := s.findRefreshToken(dbctx, req.Token)
+	if err != nil || stored.RevokedDate.Valid || time.Now().In(time.UTC).After(stored.ExpiredDate) {
+		return nil, "", nil
+	}
+	user, err := s.userR.GetByID(dbctx, stored.UserID)
+	if err != nil || !user.Status {
+		return nil, "", nil
+	}
+
+	return &utils.TokenMetaData{
+		ID:       user.ID,
+		Code:     user.Code,
+		Role:     user.Role,
+		ClientID: stored.ClientID.String,
+		Scope:    strings.Join(stored.Scopes, " "),
+		IssuedAt: stored.CreatedDate.Unix(),
+		Expires:  stored.ExpiredDate.Unix(),
+	}, model.OAUTH_TOKEN_HINT_REFRESH, nil
+}
+
+func (s *oauthService) Revoke(dbctx db.DBCtx, req requests.OAuthTokenActionRequest) error {
+	// Authenticate client
+	client, err := s.authenticate(dbctx, req.ClientID, req.ClientSecret)
+	if err != nil {
+		return err
+	}
+
+	// Access token, only the client it was issued to may revoke it
+	if req.TokenTypeHint != model.OAUTH_TOKEN_HINT_REFRESH {
+		if tokenMetaData, err := utils.DecodeScopedJWT(req.Token, utils.JWT_TYPE_OAUTH); err == nil && tokenMetaData != nil {
+			if tokenMetaData.ClientID != client.ClientID {
+				return nil
+			}
+			return utils.RevokeToken(tokenMetaData)
+		}
+	}
+
+	// Refresh token revokes its whole family, unknown tokens are ignored, see RFC 7009 section 2.2
+	stored, err := s.findRefreshToken(dbctx, req.Token)
+	if err != nil || stored.ClientID.String != client.ClientID {
+		return nil
+	}
+
+	return s.refreshR.RevokeFamily(dbctx, stored.Family)
+}
+
+func (s *oauthService) exchangeCode(dbctx db.DBCtx, req requests.OAuthTokenRequest, client model.ApiClient) (model.AuthToken, []string, error) {
+	var token model.AuthToken
+
+	// Consume code
+	var code model.OAuthCode
+	found, err := takeJSON(dbctx.Ctx, s.redis.RedisDefault, oauthCodePrefix+utils.HashToken(req.Code), &code)
+	if err != nil {
+		return token, nil, err
+	}
+	if !found || code.ClientID != client.ClientID || code.RedirectURI != req.RedirectURI {
+		return token, nil, model.NewOAuthError("invalid_grant", "invalid authorization code")
+	}
+
+	// Check pkce verifier
+	if !verifyCodeChallenge(code.CodeChallenge, code.CodeChallengeMethod, req.CodeVerifier) {
+		return token, nil, model.NewOAuthError("invalid_grant", "invalid code verifier")
+	}
+
+	// Get user
+	user, err := s.userR.GetByID(dbctx, code.UserID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = model.NewOAuthError("invalid_grant", "user is not found")
+		}
+		return token, nil, err
+	}
+	if !user.Status {
+		return token, nil, model.NewOAuthError("invalid_grant", "user is not active")
+	}
+
+	// Generate token with a new refresh token family
+	token, err = s.issueToken(dbctx, user, client, code.Scopes, common.CodeGenerator(model.OAUTH_FAMILY_PREFIX, 16))
+
+	return token, code.Scopes, err
+}
+
+func (s *oauthService) clientCredentials(req requests.OAuthTokenRequest, client model.ApiClient) (model.AuthToken, []string, error) {
+	var token model.AuthToken
+
+	// Client acts on its own behalf within its registered scopes
+	scopes, err := narrowScopes(req.Scope, client.Scopes)
+	if err != nil {
+		return token, nil, err
+	}
+
+	// No refresh token, the client requests a new token instead
+	token.AccessToken, token.AccessExpires, err = utils.GenerateOAuthJWT(0, client.Code, "", client.ClientID, scopes, s.jwtCfg.AccessTTL)
+
+	return token, scopes, err
+}
+
+func (s *oauthService) refresh(dbctx db.DBCtx, req requests.OAuthTokenRequest, client model.ApiClient) (model.AuthToken, []string, error) {
+	var token model.AuthToken
+
+	// Get stored refresh token of the client
+	stored, err := s.findRefreshToken(dbctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) {
+			err = model.WrapOAuthError("invalid_grant", err)
+		}
+		return token, nil, err
+	}
+	if stored.ClientID.String != client.ClientID {
+		return token, nil, model.WrapOAuthError("invalid_grant", ErrInvalidRefreshToken)
+	}
+
+	// Reuse of a rotated token, revoke the whole family
+	if stored.RevokedDate.Valid {
+		if err := s.refreshR.RevokeFamily(dbctx, stored.Family); err != nil {
+			return token, nil, err
+		}
+		return token, nil, model.WrapOAuthError("invalid_grant", ErrRefreshTokenReused)
+	}
+
+	// Check expired time
+	if time.Now().In(time.UTC).After(stored.ExpiredDate) {
+		return token, nil, model.NewOAuthError("invalid_grant", "refresh token expired")
+	}
+
+	// Scope may only be narrowed
+	scopes, err := narrowScopes(req.Scope, stored.Scopes)
+	if err != nil {
+		return token, nil, err
+	}
+
+	// Rotate, a concurrent rotation is treated as reuse
+	if err := s.refreshR.Revoke(dbctx, stored.ID); err != nil {
+		if err := s.refreshR.RevokeFamily(dbctx, stored.Family); err != nil {
+			return token, nil, err
+		}
+		return token, nil, model.WrapOAuthError("invalid_grant", ErrRefreshTokenReused)
+	}
+
+	// Get user
+	user, err := s.userR.GetByID(dbctx, stored.UserID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = model.NewOAuthError("invalid_grant", "user is not found")
+		}
+		return token, nil, err
+	}
+	if !user.Status {
+		return token, nil, model.NewOAuthError("invalid_grant", "user is not active")
+	}
+
+	// Drop scopes the user role no longer holds
+	permissions, err := s.permissionS.FindRolePermissions(dbctx, user.Role)
+	if err != nil {
+		return token, nil, err
+	}
+	scopes = intersectScopes(scopes, permissions)
+
+	// Generate token in the same family
+	token, err = s.issueToken(dbctx, user, client, scopes, stored.Family)
+
+	return token, scopes, err
+}
+
+// issueToken generate oauth access token and store a refresh token of the client in family
+func (s *oauthService) issueToken(dbctx db.DBCtx, user model.User, client model.ApiClient, scopes []string, family string) (model.AuthToken, error) {
+	var token model.AuthToken
+
+	// Generate access token
+	accessToken, accessExpires, err := utils.GenerateOAuthJWT(user.ID, user.Code, user.Role, client.ClientID, scopes, s.jwtCfg.AccessTTL)
+	if err != nil {
+		return token, err
+	}
+
+	// Generate refresh token
+	refreshToken, refreshHash, refreshExpires, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return token, err
+	}
+	_, err = s.refreshR.Insert(dbctx, model.RefreshToken{
+		UserID:      user.ID,
+		TokenHash:   refreshHash,
+		Family:      family,
+		ClientID:    sql.NullString{Valid: true, String: client.ClientID},
+		Scopes:      scopes,
+		ExpiredDate: refreshExpires,
+	})
+	if err != nil {
+		return token, err
+	}
+
+	token = model.AuthToken{
+		AccessToken:    accessToken,
+		AccessExpires:  accessExpires,
+		RefreshToken:   refreshToken,
+		RefreshExpires: refreshExpires.Unix(),
+	}
+
+	return token, nil
+}
+
+// authenticate client credentials, failures are answered as invalid_client
+func (s *oauthService) authenticate(dbctx db.DBCtx, clientID, secret string) (model.ApiClient, error) {
+	client, err := s.clientS.Authenticate(dbctx, clientID, secret)
+	if errors.Is(err, ErrInvalidClient) {
+		err = model.WrapOAuthError("invalid_client", err)
+	}
+
+	return client, err
+}
+
+// findRefreshToken find a refresh token issued to an oauth client
+func (s *oauthService) findRefreshToken(dbctx db.DBCtx, refreshToken string) (model.RefreshToken, error) {
+	stored, err := s.refreshR.GetByTokenHash(dbctx, utils.HashToken(refreshToken))
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrInvalidRefreshToken
+		}
+		return stored, err
+	}
+	if !stored.ClientID.Valid {
+		return stored, ErrInvalidRefreshToken
+	}
+
+	return stored, nil
+}
+
+// grantScopes requested scopes, or every client scope when none, limited to the permissions of the role
+func (s *oauthService) grantScopes(dbctx db.DBCtx, requested string, clientScopes []string, role string) ([]string, error) {
+	scopes, err := narrowScopes(requested, clientScopes)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.permissionS.FindRolePermissions(dbctx, role)
+	if err != nil {
+		return nil, err
+	}
+	scopes = intersectScopes(scopes, permissions)
+	if len(scopes) <= 0 {
+		return nil, model.NewOAuthError("invalid_scope", "no requested scope is permitted for the user")
+	}
+
+	return scopes, nil
+}
+
+// narrowScopes parse space separated scopes which must all be allowed, empty means every allowed scope
+func narrowScopes(requested string, allowed []string) ([]string, error) {
+	scopes := strings.Fields(requested)
+	if len(scopes) <= 0 {
+		return nonNilStrings(allowed), nil
+	}
+	if err := checkScopes(scopes, allowed); err != nil {
+		return nil, model.NewOAuthError("invalid_scope", err.Error())
+	}
+
+	return scopes, nil
+}
+
+func intersectScopes(scopes, permissions []string) []string {
+	arrStr := new(common.ArrStr)
+	granted := []string{}
+	for _, scope := range scopes {
+		if exists, _ := arrStr.InArray(scope, permissions); exists {
+			granted = append(granted, scope)
+		}
+	}
+
+	return granted
+}
+
+// verifyCodeChallenge check the pkce verifier of the code, see RFC 7636 section 4.6
+func verifyCodeChallenge(challenge, method, verifier string) bool {
+	if len(challenge) <= 0 {
+		return true
+	}
+	if len(verifier) <= 0 {
+		return false
+	}
+
+	expected := verifier
+	if method == "S256" {
+		sum := sha256.Sum256([]byte(verifier))
+		expected = base64.RawURLEncoding.EncodeToString(sum[:])
+	}
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
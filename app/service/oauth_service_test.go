@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fiber-starter/pkg/utils"
+	"testing"
+)
+
+func TestVerifyCodeChallengeS256(t *testing.T) {
+	// Example of RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	if !verifyCodeChallenge(challenge, "S256", verifier) {
+		t.Error("verifier of the challenge is rejected")
+	}
+	if verifyCodeChallenge(challenge, "S256", verifier+"x") {
+		t.Error("other verifier is accepted")
+	}
+	if verifyCodeChallenge(challenge, "S256", challenge) {
+		t.Error("challenge is accepted as its own verifier")
+	}
+	if verifyCodeChallenge(challenge, "S256", "") {
+		t.Error("missing verifier is accepted")
+	}
+
+	// Pair generated for the providers
+	verifier, challenge, err := utils.GeneratePKCE()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !verifyCodeChallenge(challenge, "S256", verifier) {
+		t.Error("generated verifier is rejected")
+	}
+}
+
+func TestVerifyCodeChallengePlain(t *testing.T) {
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	for _, method := range []string{"plain", ""} {
+		if !verifyCodeChallenge(verifier, method, verifier) {
+			t.Errorf("method %q: verifier is rejected", method)
+		}
+		if verifyCodeChallenge(verifier, method, verifier+"x") {
+			t.Errorf("method %q: other verifier is accepted", method)
+		}
+	}
+
+	// S256 verifier does not pass as plain
+	if verifyCodeChallenge("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", "plain", verifier) {
+		t.Error("S256 challenge is accepted as plain")
+	}
+}
+
+func TestVerifyCodeChallengeWithoutPKCE(t *testing.T) {
+	// Codes issued without a challenge need no verifier
+	if !verifyCodeChallenge("", "", "") {
+		t.Error("code without challenge is rejected")
+	}
+}
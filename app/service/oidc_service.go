@@ -0,0 +1,326 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	oidcStatePrefix  = "oidc:state:"
+	oidcSignupPrefix = "oidc:signup:"
+	oidcAlphabet     = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+var (
+	ErrInvalidOIDCState    = errors.New("invalid or expired sign in state")
+	ErrOIDCEmailUnverified = errors.New("email of the identity provider account is not verified")
+)
+
+type OIDCService interface {
+	Begin(ctx context.Context, provider string, client model.ApiClient) (string, string, error)
+	Callback(dbctx db.DBCtx, provider string, req requests.OIDCCallbackRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, string, error)
+	Signup(dbctx db.DBCtx, req requests.OIDCSignupRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error)
+}
+
+// oidcState kept between the authorization request and its callback
+type oidcState struct {
+	Provider     string `json:"provider"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	ClientID     string `json:"client_id"`
+}
+
+// oidcSignup verified identity waiting for the data required to create its user
+type oidcSignup struct {
+	Provider string           `json:"provider"`
+	Claims   utils.OIDCClaims `json:"claims"`
+	ClientID string           `json:"client_id"`
+}
+
+type oidcService struct {
+	userR     repository.UserRepository
+	roleR     repository.RoleRepository
+	identityR repository.UserIdentityRepository
+	authS     AuthService
+	redis     *config.Redis
+}
+
+func NewOIDCService(user repository.UserRepository, role repository.RoleRepository, identity repository.UserIdentityRepository, auth AuthService, redis *config.Redis) *oidcService {
+	return &oidcService{user, role, identity, auth, redis}
+}
+
+func (s *oidcService) Begin(ctx context.Context, provider string, client model.ApiClient) (string, string, error) {
+	oidcProvider, err := utils.GetOIDCProvider(provider)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Generate state, nonce and pkce verifier
+	state, err := utils.RandomString(32, oidcAlphabet)
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.RandomString(32, oidcAlphabet)
+	if err != nil {
+		return "", "", err
+	}
+	verifier, challenge, err := utils.GeneratePKCE()
+	if err != nil {
+		return "", "", err
+	}
+
+	// Keep state bound to the provider and client
+	err = setJSON(ctx, s.redis.RedisDefault, oidcStatePrefix+state, oidcState{
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ClientID:     client.ClientID,
+	}, model.OIDC_STATE_EXPIRED_TIME*time.Minute)
+	if err != nil {
+		return "", "", err
+	}
+
+	authURL, err := oidcProvider.AuthCodeURL(ctx, state, nonce, challenge)
+
+	return authURL, state, err
+}
+
+func (s *oidcService) Callback(dbctx db.DBCtx, provider string, req requests.OIDCCallbackRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, string, error) {
+	var user model.User
+	var token model.AuthToken
+
+	oidcProvider, err := utils.GetOIDCProvider(provider)
+	if err != nil {
+		return user, token, "", err
+	}
+
+	// Consume state, a state is usable once
+	var state oidcState
+	found, err := takeJSON(dbctx.Ctx, s.redis.RedisDefault, oidcStatePrefix+req.State, &state)
+	if err != nil {
+		return user, token, "", err
+	}
+	if !found || state.Provider != provider || state.ClientID != client.ClientID {
+		return user, token, "", ErrInvalidOIDCState
+	}
+
+	// Exchange code and verify id token
+	claims, err := oidcProvider.Exchange(dbctx.Ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		return user, token, "", err
+	}
+
+	// Find user of identity, or link by verified email
+	user, identity, err := s.findIdentityUser(dbctx, provider, claims)
+	if err != nil {
+		return user, token, "", err
+	}
+
+	// Unknown email needs a phone to create the user
+	if user.ID <= 0 && len(req.Phone) <= 0 {
+		signupToken, err := utils.RandomString(32, oidcAlphabet)
+		if err != nil {
+			return user, token, "", err
+		}
+		err = setJSON(dbctx.Ctx, s.redis.RedisDefault, oidcSignupPrefix+signupToken, oidcSignup{
+			Provider: provider,
+			Claims:   claims,
+			ClientID: client.ClientID,
+		}, model.OIDC_SIGNUP_EXPIRED_TIME*time.Minute)
+
+		return user, token, signupToken, err
+	}
+
+	user, token, err = s.login(dbctx, provider, claims, user, identity, req.Phone, client, device)
+
+	return user, token, "", err
+}
+
+func (s *oidcService) Signup(dbctx db.DBCtx, req requests.OIDCSignupRequest, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error) {
+	var user model.User
+	var token model.AuthToken
+
+	// Consume signup token
+	var signup oidcSignup
+	found, err := takeJSON(dbctx.Ctx, s.redis.RedisDefault, oidcSignupPrefix+req.SignupToken, &signup)
+	if err != nil {
+		return user, token, err
+	}
+	if !found || signup.ClientID != client.ClientID {
+		return user, token, ErrInvalidOIDCState
+	}
+
+	// Identity may have been linked meanwhile
+	user, identity, err := s.findIdentityUser(dbctx, signup.Provider, signup.Claims)
+	if err != nil {
+		return user, token, err
+	}
+
+	return s.login(dbctx, signup.Provider, signup.Claims, user, identity, req.Phone, client, device)
+}
+
+// findIdentityUser find the user linked to the identity, or the user owning its verified email,
+// returns an empty user when none exists
+func (s *oidcService) findIdentityUser(dbctx db.DBCtx, provider string, claims utils.OIDCClaims) (model.User, model.UserIdentity, error) {
+	// Linked identity
+	identity, err := s.identityR.GetByProviderSubject(dbctx, provider, claims.Subject)
+	if err == nil {
+		user, err := s.userR.GetByID(dbctx, identity.UserID)
+		return user, identity, err
+	}
+	if err.Error() != pgx.ErrNoRows.Error() {
+		return model.User{}, identity, err
+	}
+
+	// Linking by email requires the provider to have verified it
+	if len(claims.Email) <= 0 || !claims.EmailVerified {
+		return model.User{}, identity, ErrOIDCEmailUnverified
+	}
+	user, err := s.userR.GetByEmail(dbctx, claims.Email)
+	if err != nil && err.Error() != pgx.ErrNoRows.Error() {
+		return user, identity, err
+	}
+
+	return user, identity, nil
+}
+
+// login create the user when needed, link the identity and issue the login token
+func (s *oidcService) login(dbctx db.DBCtx, provider string, claims utils.OIDCClaims, user model.User, identity model.UserIdentity, phone string, client model.ApiClient, device model.Device) (model.User, model.AuthToken, error) {
+	var token model.AuthToken
+	var err error
+
+	// Create user of a new email
+	if user.ID <= 0 {
+		user, err = s.createUser(dbctx, claims, phone)
+		if err != nil {
+			return user, token, err
+		}
+	}
+
+	// Adjustment login user role of client
+	err = client.CheckAllowedRole(user.Role)
+	if err != nil {
+		return user, token, err
+	}
+
+	// Link identity or track its login
+	if identity.ID <= 0 {
+		_, err = s.identityR.Insert(dbctx, model.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  claims.Subject,
+			Email:    sql.NullString{Valid: len(claims.Email) > 0, String: claims.Email},
+		})
+	} else {
+		err = s.identityR.UpdateLastLogin(dbctx, identity.ID)
+	}
+	if err != nil {
+		return user, token, err
+	}
+
+	// Email verified by the provider activates the user
+	if !user.Status {
+		err = s.userR.UpdateStatusByEmailOrPhone(dbctx, true, user.Email)
+		if err != nil {
+			return user, token, err
+		}
+		user.Status = true
+	}
+
+	token, err = s.authS.IssueLoginToken(dbctx, user, device)
+
+	return user, token, err
+}
+
+func (s *oidcService) createUser(dbctx db.DBCtx, claims utils.OIDCClaims, phone string) (model.User, error) {
+	var user model.User
+
+	// Phone validation
+	_, _, validPhone := common.IsPhone(phone)
+	if !validPhone {
+		return user, fmt.Errorf(`phone %s is invalid`, phone)
+	}
+
+	// Random password, the user signs in through the provider or resets it
+	password, err := utils.RandomPassword()
+	if err != nil {
+		return user, err
+	}
+	passwordHash, err := utils.HashPassword(password)
+	if err != nil {
+		return user, err
+	}
+
+	// Set data
+	name := claims.Name
+	if len(name) <= 0 {
+		name = claims.Email
+	}
+	code := common.CodeGenerator(model.USER_PREFIX, 5)
+	user = model.User{
+		Code:      code,
+		Status:    true,
+		Name:      name,
+		Email:     claims.Email,
+		Role:      model.ROLE_CUST,
+		Phone:     phone,
+		Password:  passwordHash,
+		CreatedBy: code,
+	}
+
+	// Set role slug
+	roleID, err := s.roleR.GetIDBySlug(dbctx, user.Role)
+	if err != nil {
+		return user, err
+	}
+	user.RoleID = int32(roleID)
+
+	return s.userR.Insert(dbctx, user)
+}
+
+// setJSON store value as json in redis
+func setJSON(ctx context.Context, rdb *redis.Client, key string, value interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	return rdb.Set(ctx, key, data, ttl).Err()
+}
+
+// takeJSON get and delete the key atomically, returns false when the key is missing
+func takeJSON(ctx context.Context, rdb *redis.Client, key string, value interface{}) (bool, error) {
+	var get *redis.StringCmd
+	_, err := rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		pipe.Del(ctx, key)
+		return nil
+	})
+	if err == redis.Nil {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	data, err := get.Bytes()
+	if err != nil {
+		return false, nil
+	}
+
+	return true, json.Unmarshal(data, value)
+}
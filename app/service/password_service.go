@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"fiber-starter/pkg/utils"
+	"strconv"
+)
+
+type PasswordService interface {
+	Validate(dbctx db.DBCtx, password string, user model.User) error
+	Hash(password string) (string, error)
+	Remember(dbctx db.DBCtx, userID int64, passwordHash string) error
+	Set(dbctx db.DBCtx, user model.User, password string) error
+}
+
+type passwordService struct {
+	userR    repository.UserRepository
+	historyR repository.PasswordHistoryRepository
+}
+
+func NewPasswordService(user repository.UserRepository, history repository.PasswordHistoryRepository) *passwordService {
+	return &passwordService{user, history}
+}
+
+// Validate check the password against the policy and, for an existing user, its previous passwords
+func (s *passwordService) Validate(dbctx db.DBCtx, password string, user model.User) error {
+	err := utils.CheckPasswordPolicy(password, user.Name, user.Email)
+	if err != nil {
+		return err
+	}
+
+	historySize := utils.PasswordHistorySize()
+	if user.ID <= 0 || historySize <= 0 {
+		return nil
+	}
+
+	// Current password and the last passwords of history
+	hashes := []string{user.Password}
+	histories, err := s.historyR.GetLatestByUserID(dbctx, user.ID, historySize)
+	if err != nil {
+		return err
+	}
+	for _, history := range histories {
+		hashes = append(hashes, history.PasswordHash)
+	}
+
+	for _, hash := range hashes {
+		if match, _, _ := utils.VerifyPassword(hash, password); match {
+			return utils.PasswordPolicyErrors{{Key: config.PASSWORD_REUSED, Param: strconv.Itoa(historySize)}}
+		}
+	}
+
+	return nil
+}
+
+func (s *passwordService) Hash(password string) (string, error) {
+	return utils.HashPassword(password)
+}
+
+// Remember keep the password hash in history, only the last passwords of the policy are kept
+func (s *passwordService) Remember(dbctx db.DBCtx, userID int64, passwordHash string) error {
+	historySize := utils.PasswordHistorySize()
+	if historySize <= 0 {
+		return nil
+	}
+
+	_, err := s.historyR.Insert(dbctx, model.PasswordHistory{UserID: userID, PasswordHash: passwordHash})
+	if err != nil {
+		return err
+	}
+
+	return s.historyR.DeleteOlderByUserID(dbctx, userID, historySize)
+}
+
+// Set check the password policy, then store the password of user and keep it in history
+func (s *passwordService) Set(dbctx db.DBCtx, user model.User, password string) error {
+	err := s.Validate(dbctx, password, user)
+	if err != nil {
+		return err
+	}
+
+	passwordHash, err := s.Hash(password)
+	if err != nil {
+		return err
+	}
+	err = s.userR.UpdatePasswordByEmailOrPhone(dbctx, passwordHash, user.Email)
+	if err != nil {
+		return err
+	}
+
+	return s.Remember(dbctx, user.ID, passwordHash)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	permissionCachePrefix = "permission:role:"
+	permissionCacheTTL    = 10 * time.Minute
+)
+
+type PermissionService interface {
+	FindRolePermissions(dbctx db.DBCtx, role string) ([]string, error)
+	ForgetRolePermissions(role string) error
+}
+
+type permissionService struct {
+	permissionR repository.PermissionRepository
+	redis       *config.Redis
+}
+
+func NewPermissionService(permission repository.PermissionRepository, redis *config.Redis) *permissionService {
+	return &permissionService{permission, redis}
+}
+
+func (s *permissionService) FindRolePermissions(dbctx db.DBCtx, role string) ([]string, error) {
+	// Get cached permissions
+	var permissions []string
+	cached, err := s.redis.RedisCache.Get(dbctx.Ctx, permissionCachePrefix+role).Bytes()
+	if err == nil {
+		err = json.Unmarshal(cached, &permissions)
+		return permissions, err
+	}
+	if err != redis.Nil {
+		return permissions, err
+	}
+
+	// Get permissions of role
+	permissions, err = s.permissionR.GetSlugsByRoleSlug(dbctx, role)
+	if err != nil {
+		return permissions, err
+	}
+
+	// Set cache
+	data, err := json.Marshal(permissions)
+	if err != nil {
+		return permissions, err
+	}
+	err = s.redis.RedisCache.Set(dbctx.Ctx, permissionCachePrefix+role, data, permissionCacheTTL).Err()
+
+	return permissions, err
+}
+
+func (s *permissionService) ForgetRolePermissions(role string) error {
+	return s.redis.RedisCache.Del(context.Background(), permissionCachePrefix+role).Err()
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fiber-starter/app/api/requests"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/db"
+	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+type ProfileService interface {
+	Find(dbctx db.DBCtx, userID int64) (model.User, error)
+	Update(dbctx db.DBCtx, userID int64, req requests.ProfileUpdateRequest) (model.User, error)
+	ChangePassword(dbctx db.DBCtx, userID int64, req requests.ProfileChangePasswordRequest) error
+	RequestEmailChange(dbctx db.DBCtx, userID int64, req requests.ProfileChangeEmailRequest, client model.ApiClient) (string, error)
+	ConfirmEmailChange(dbctx db.DBCtx, userID int64, req requests.ProfileVerifyChangeRequest, client model.ApiClient) (model.User, error)
+	RequestPhoneChange(dbctx db.DBCtx, userID int64, req requests.ProfileChangePhoneRequest, client model.ApiClient) (string, error)
+	ConfirmPhoneChange(dbctx db.DBCtx, userID int64, req requests.ProfileVerifyChangeRequest) (model.User, error)
+}
+
+var (
+	ErrEmailUsed = errors.New("email is already used")
+	ErrPhoneUsed = errors.New("phone is already used")
+)
+
+type profileService struct {
+	userR     repository.UserRepository
+	passwordS PasswordService
+	authS     AuthService
+	notifyS   NotificationService
+}
+
+func NewProfileService(user repository.UserRepository, password PasswordService, auth AuthService, notify NotificationService) *profileService {
+	return &profileService{user, password, auth, notify}
+}
+
+func (s *profileService) Find(dbctx db.DBCtx, userID int64) (model.User, error) {
+	return s.userR.GetByID(dbctx, userID)
+}
+
+func (s *profileService) Update(dbctx db.DBCtx, userID int64, req requests.ProfileUpdateRequest) (model.User, error) {
+	// Get user
+	user, err := s.userR.GetByID(dbctx, userID)
+	if err != nil {
+		return user, err
+	}
+
+	// Check version
+	if user.Version != int32(req.Version) {
+		return user, errors.New("version is not match")
+	}
+
+	// Only sent fields are changed
+	if req.Name != nil {
+		user.Name = *req.Name
+	}
+	if req.Address != nil {
+		user.Address = sql.NullString{Valid: true, String: *req.Address}
+	}
+	if req.Img != nil {
+		user.Img = sql.NullString{Valid: true, String: *req.Img}
+	}
+	user.Version++
+	user.UpdatedDate = sql.NullTime{Valid: true, Time: time.Now().In(time.UTC)}
+	user.UpdatedBy = sql.NullString{Valid: true, String: user.Code}
+
+	return user, s.userR.Update(dbctx, user)
+}
+
+func (s *profileService) ChangePassword(dbctx db.DBCtx, userID int64, req requests.ProfileChangePasswordRequest) error {
+	// Compare password
+	if req.Password != req.ConfirmPassword {
+		return fmt.Errorf("%s", "password is not match.")
+	}
+
+	// Get user and check current password
+	user, err := s.checkPassword(dbctx, userID, req.CurrentPassword)
+	if err != nil {
+		return err
+	}
+
+	err = s.passwordS.Set(dbctx, user, req.Password)
+	if err != nil {
+		return err
+	}
+
+	return s.notifyS.Notify(dbctx, utils.MAIL_FOR_PASSWORDCHANGE, user, utils.DataAccountNotice{
+		Name: user.Name,
+		Date: noticeDate(time.Now()),
+	})
+}
+
+func (s *profileService) RequestEmailChange(dbctx db.DBCtx, userID int64, req requests.ProfileChangeEmailRequest, client model.ApiClient) (string, error) {
+	// Get user and check current password
+	user, err := s.checkPassword(dbctx, userID, req.Password)
+	if err != nil {
+		return "", err
+	}
+	if user.Email == req.Email {
+		return "", errors.New("email is not changed")
+	}
+
+	// Check email is not used
+	err = s.checkUnused(dbctx, req.Email, ErrEmailUsed)
+	if err != nil {
+		return "", err
+	}
+
+	// Token is bound to the user and the new email
+	target := model.User{ID: user.ID, Code: user.Code, Phone: user.Phone, Email: req.Email}
+	otpToken, err := s.authS.GenerateOTPToken(dbctx, client.Channel, utils.MAIL_FOR_CHANGEEMAIL, target)
+	if err != nil {
+		return "", err
+	}
+
+	// New email is verified by receiving the token
+	err = s.authS.SendOTPToken(dbctx, target, client, utils.MAIL_FOR_CHANGEEMAIL, otpToken, model.OTP_VIA_EMAIL)
+
+	return req.Email, err
+}
+
+func (s *profileService) ConfirmEmailChange(dbctx db.DBCtx, userID int64, req requests.ProfileVerifyChangeRequest, client model.ApiClient) (model.User, error) {
+	// Get user
+	user, err := s.userR.GetByID(dbctx, userID)
+	if err != nil {
+		return user, err
+	}
+
+	// Consume token of the new email
+	owner, err := s.authS.ConsumeOTPToken(dbctx, client.Channel, utils.MAIL_FOR_CHANGEEMAIL, req.EmailPhone, req.OTPToken)
+	if err != nil {
+		return user, err
+	}
+	if (client.Channel == utils.ChannelWeb && owner.ID != user.ID) || (client.Channel != utils.ChannelWeb && owner.Phone != user.Phone) {
+		return user, ErrInvalidOTP
+	}
+
+	// Email may have been taken meanwhile
+	err = s.checkUnused(dbctx, owner.Email, ErrEmailUsed)
+	if err != nil {
+		return user, err
+	}
+
+	err = s.userR.UpdateEmail(dbctx, user.ID, owner.Email, user.Code)
+	if err != nil {
+		return user, err
+	}
+
+	// Notice goes to the previous email
+	err = s.notifyS.Notify(dbctx, utils.MAIL_FOR_EMAILCHANGE, user, utils.DataAccountNotice{
+		Name:   user.Name,
+		Detail: owner.Email,
+		Date:   noticeDate(time.Now()),
+	})
+	user.Email = owner.Email
+	user.Version++
+
+	return user, err
+}
+
+func (s *profileService) RequestPhoneChange(dbctx db.DBCtx, userID int64, req requests.ProfileChangePhoneRequest, client model.ApiClient) (string, error) {
+	// Phone validation
+	_, _, validPhone := common.IsPhone(req.Phone)
+	if !validPhone {
+		return "", fmt.Errorf(`phone %s is invalid`, req.Phone)
+	}
+
+	// Get user
+	user, err := s.userR.GetByID(dbctx, userID)
+	if err != nil {
+		return "", err
+	}
+	if user.Phone == req.Phone {
+		return "", errors.New("phone is not changed")
+	}
+
+	// Check phone is not used
+	err = s.checkUnused(dbctx, req.Phone, ErrPhoneUsed)
+	if err != nil {
+		return "", err
+	}
+
+	// Phone is always verified by otp, bound to the user email and the new phone
+	target := model.User{ID: user.ID, Code: user.Code, Phone: req.Phone, Email: user.Email}
+	otpToken, err := s.authS.GenerateOTPToken(dbctx, utils.ChannelApp, utils.MAIL_FOR_CHANGEPHONE, target)
+	if err != nil {
+		return "", err
+	}
+	otpClient := client
+	otpClient.Channel = utils.ChannelApp
+	err = s.authS.SendOTPToken(dbctx, target, otpClient, utils.MAIL_FOR_CHANGEPHONE, otpToken, model.OTP_VIA_PHONE)
+
+	return req.Phone, err
+}
+
+func (s *profileService) ConfirmPhoneChange(dbctx db.DBCtx, userID int64, req requests.ProfileVerifyChangeRequest) (model.User, error) {
+	// Get user
+	user, err := s.userR.GetByID(dbctx, userID)
+	if err != nil {
+		return user, err
+	}
+
+	// Consume otp of the new phone
+	owner, err := s.authS.ConsumeOTPToken(dbctx, utils.ChannelApp, utils.MAIL_FOR_CHANGEPHONE, req.EmailPhone, req.OTPToken)
+	if err != nil {
+		return user, err
+	}
+	if owner.Email != user.Email {
+		return user, ErrInvalidOTP
+	}
+
+	// Phone may have been taken meanwhile
+	err = s.checkUnused(dbctx, owner.Phone, ErrPhoneUsed)
+	if err != nil {
+		return user, err
+	}
+
+	err = s.userR.UpdatePhone(dbctx, user.ID, owner.Phone, user.Code)
+	if err != nil {
+		return user, err
+	}
+
+	// Notice goes to the previous phone
+	err = s.notifyS.Notify(dbctx, utils.MAIL_FOR_PHONECHANGE, user, utils.DataAccountNotice{
+		Name:   user.Name,
+		Detail: owner.Phone,
+		Date:   noticeDate(time.Now()),
+	})
+	user.Phone = owner.Phone
+	user.Version++
+
+	return user, err
+}
+
+// checkPassword get the user and check the password is its current one
+func (s *profileService) checkPassword(dbctx db.DBCtx, userID int64, password string) (model.User, error) {
+	user, err := s.userR.GetByID(dbctx, userID)
+	if err != nil {
+		return user, err
+	}
+
+	match, _, err := utils.VerifyPassword(user.Password, password)
+	if err != nil {
+		return user, err
+	}
+	if !match {
+		return user, ErrInvalidCredential
+	}
+
+	return user, nil
+}
+
+// checkUnused check no user has the email or phone
+func (s *profileService) checkUnused(dbctx db.DBCtx, emailPhone string, usedErr error) error {
+	_, err := s.userR.GetByEmailOrPhone(dbctx, emailPhone)
+	if err == nil {
+		return usedErr
+	}
+	if err.Error() != pgx.ErrNoRows.Error() {
+		return err
+	}
+
+	return nil
+}
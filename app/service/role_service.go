@@ -8,6 +8,8 @@ import (
 	"fiber-starter/app/repository"
 	"fiber-starter/db"
 	"fiber-starter/pkg/common"
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -20,14 +22,20 @@ type RoleService interface {
 	UpdateRole(dbctx db.DBCtx, req requests.RoleUpdateRequest, handlerBy, code string) (model.Role, error)
 	DeleteRole(dbctx db.DBCtx, handlerBy, code string) error
 	FindAllRole(dbctx db.DBCtx, c *fiber.Ctx) ([]model.Role, int64, common.PaginateQueryOffset, error)
+	FindPermissions(dbctx db.DBCtx, code string) (model.Role, []string, error)
+	GrantPermissions(dbctx db.DBCtx, req requests.RolePermissionRequest, handlerBy, code string, catalogue []string) (model.Role, []string, error)
+	RevokePermissions(dbctx db.DBCtx, req requests.RolePermissionRequest, handlerBy, code string) (model.Role, []string, error)
+	ForgetPermissions(role model.Role) error
 }
 
 type roleService struct {
-	roleR repository.RoleRepository
+	roleR       repository.RoleRepository
+	permissionR repository.PermissionRepository
+	permissionS PermissionService
 }
 
-func NewRoleService(role repository.RoleRepository) *roleService {
-	return &roleService{role}
+func NewRoleService(role repository.RoleRepository, permissionR repository.PermissionRepository, permissionS PermissionService) *roleService {
+	return &roleService{role, permissionR, permissionS}
 }
 
 func (s *roleService) FindRole(dbctx db.DBCtx, code string) (model.Role, error) {
@@ -125,3 +133,87 @@ func (s *roleService) FindAllRole(dbctx db.DBCtx, c *fiber.Ctx) ([]model.Role, i
 
 	return roleList, total, pg, err
 }
+
+func (s *roleService) FindPermissions(dbctx db.DBCtx, code string) (model.Role, []string, error) {
+	// Find role
+	role, err := s.FindRole(dbctx, code)
+	if err != nil {
+		return role, nil, err
+	}
+
+	permissions, err := s.permissionR.GetSlugsByRoleID(dbctx, role.ID)
+
+	return role, permissions, err
+}
+
+func (s *roleService) GrantPermissions(dbctx db.DBCtx, req requests.RolePermissionRequest, handlerBy, code string, catalogue []string) (model.Role, []string, error) {
+	// Check permissions in catalogue
+	arrStr := new(common.ArrStr)
+	for _, permission := range req.Permissions {
+		if exists, _ := arrStr.InArray(permission, catalogue); !exists {
+			return model.Role{}, nil, fmt.Errorf(`invalid permission %s`, permission)
+		}
+	}
+
+	// Bump role version
+	role, permissions, err := s.bumpPermissionVersion(dbctx, req.Version, handlerBy, code)
+	if err != nil {
+		return role, permissions, err
+	}
+
+	// Grant permissions
+	err = s.permissionR.GrantToRole(dbctx, role.ID, req.Permissions, handlerBy)
+	if err != nil {
+		return role, permissions, err
+	}
+	permissions = arrStr.Unique(append(permissions, req.Permissions...))
+	sort.Strings(permissions)
+
+	return role, permissions, err
+}
+
+func (s *roleService) RevokePermissions(dbctx db.DBCtx, req requests.RolePermissionRequest, handlerBy, code string) (model.Role, []string, error) {
+	// Bump role version
+	role, permissions, err := s.bumpPermissionVersion(dbctx, req.Version, handlerBy, code)
+	if err != nil {
+		return role, permissions, err
+	}
+
+	// Revoke permissions
+	err = s.permissionR.RevokeFromRole(dbctx, role.ID, req.Permissions)
+	if err != nil {
+		return role, permissions, err
+	}
+	arrStr := new(common.ArrStr)
+	for _, permission := range req.Permissions {
+		permissions = arrStr.Remove(permissions, permission)
+	}
+
+	return role, permissions, err
+}
+
+func (s *roleService) ForgetPermissions(role model.Role) error {
+	return s.permissionS.ForgetRolePermissions(role.Slug)
+}
+
+// bumpPermissionVersion check role version and increase it, returns current permissions of role
+func (s *roleService) bumpPermissionVersion(dbctx db.DBCtx, reqVersion int, handlerBy, code string) (model.Role, []string, error) {
+	// Find role with permissions
+	role, permissions, err := s.FindPermissions(dbctx, code)
+	if err != nil {
+		return role, permissions, err
+	}
+
+	// Check version
+	if role.Version != int32(reqVersion) {
+		return role, permissions, errors.New("version is not match")
+	}
+
+	// Update version
+	role.Version = role.Version + 1
+	role.UpdatedDate = sql.NullTime{Valid: true, Time: time.Now().In(time.UTC)}
+	role.UpdatedBy = sql.NullString{Valid: true, String: handlerBy}
+	err = s.roleR.UpdateVersion(dbctx, role)
+
+	return role, permissions, err
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"fiber-starter/app/model"
+	"fiber-starter/app/repository"
+	"fiber-starter/config"
+	"fiber-starter/db"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	sessionRevokedPrefix = "session:revoked:"
+	sessionSeenPrefix    = "session:seen:"
+)
+
+var (
+	ErrSessionRevoked  = errors.New("session has been revoked")
+	ErrSessionNotFound = errors.New("session not found")
+)
+
+type SessionService interface {
+	Start(dbctx db.DBCtx, userID int64, family string, device model.Device) error
+	Touch(dbctx db.DBCtx, family string) error
+	FindActive(dbctx db.DBCtx, userID int64) ([]model.UserSession, error)
+	Revoke(dbctx db.DBCtx, userID, id int64) error
+	End(dbctx db.DBCtx, family string) error
+	EndAll(dbctx db.DBCtx, userID int64) error
+}
+
+type sessionService struct {
+	sessionR repository.UserSessionRepository
+	refreshR repository.RefreshTokenRepository
+	redis    *config.Redis
+	jwtCfg   config.JWTConfig
+}
+
+func NewSessionService(session repository.UserSessionRepository, refresh repository.RefreshTokenRepository, redis *config.Redis, jwtCfg config.JWTConfig) *sessionService {
+	return &sessionService{session, refresh, redis, jwtCfg}
+}
+
+func (s *sessionService) Start(dbctx db.DBCtx, userID int64, family string, device model.Device) error {
+	_, err := s.sessionR.Insert(dbctx, model.UserSession{
+		UserID:     userID,
+		Family:     family,
+		DeviceName: truncate(device.Name, 100),
+		UserAgent:  truncate(device.UserAgent, 255),
+		IP:         truncate(device.IP, 45),
+	})
+
+	return err
+}
+
+// Touch deny access tokens of a revoked session, otherwise track the session last seen time
+func (s *sessionService) Touch(dbctx db.DBCtx, family string) error {
+	revoked, err := s.redis.RedisDefault.Exists(dbctx.Ctx, sessionRevokedPrefix+family).Result()
+	if err != nil {
+		return err
+	}
+	if revoked > 0 {
+		return ErrSessionRevoked
+	}
+
+	// Last seen time is written once in the interval
+	due, err := s.redis.RedisDefault.SetNX(dbctx.Ctx, sessionSeenPrefix+family, 1, model.SESSION_TOUCH_INTERVAL*time.Second).Result()
+	if err != nil || !due {
+		return err
+	}
+
+	return s.sessionR.UpdateLastSeen(dbctx, family)
+}
+
+func (s *sessionService) FindActive(dbctx db.DBCtx, userID int64) ([]model.UserSession, error) {
+	return s.sessionR.GetActiveByUserID(dbctx, userID)
+}
+
+// Revoke end the session of the user, its refresh tokens and its access tokens
+func (s *sessionService) Revoke(dbctx db.DBCtx, userID, id int64) error {
+	session, err := s.sessionR.GetByID(dbctx, id, userID)
+	if err != nil {
+		if err.Error() == pgx.ErrNoRows.Error() {
+			err = ErrSessionNotFound
+		}
+		return err
+	}
+
+	err = s.End(dbctx, session.Family)
+	if err != nil {
+		return err
+	}
+
+	// Access tokens of the session are denied until they expire
+	return s.redis.RedisDefault.Set(dbctx.Ctx, sessionRevokedPrefix+session.Family, 1, s.jwtCfg.AccessTTL).Err()
+}
+
+func (s *sessionService) End(dbctx db.DBCtx, family string) error {
+	err := s.sessionR.RevokeByFamily(dbctx, family)
+	if err != nil {
+		return err
+	}
+
+	return s.refreshR.RevokeFamily(dbctx, family)
+}
+
+func (s *sessionService) EndAll(dbctx db.DBCtx, userID int64) error {
+	return s.sessionR.RevokeAllByUserID(dbctx, userID)
+}
+
+// truncate cut the value to the column size
+func truncate(value string, size int) string {
+	runes := []rune(value)
+	if len(runes) <= size {
+		return value
+	}
+
+	return string(runes[:size])
+}
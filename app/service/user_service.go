@@ -8,30 +8,40 @@ import (
 	"fiber-starter/app/repository"
 	"fiber-starter/db"
 	"fiber-starter/pkg/common"
+	"fiber-starter/pkg/utils"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type UserService interface {
 	FindUser(dbctx db.DBCtx, code string) (model.User, error)
 	FindAllUser(dbctx db.DBCtx, c *fiber.Ctx) ([]model.User, int64, common.PaginateQueryOffset, error)
-	CreateUser(dbctx db.DBCtx, req requests.UserCreateRequest, handlerBy, roleBy string) (model.User, error)
+	CreateUser(dbctx db.DBCtx, req requests.UserCreateRequest, handlerBy string) (model.User, error)
 	UpdateUser(dbctx db.DBCtx, req requests.UserUpdateRequest, handlerBy, code string) (model.User, error)
 	DeleteUser(dbctx db.DBCtx, handlerBy, code string) error
+	InviteUser(dbctx db.DBCtx, user model.User, client model.ApiClient, handlerBy string) error
+	ResendInvitation(dbctx db.DBCtx, code string, client model.ApiClient, handlerBy string) error
+	RevokeInvitation(dbctx db.DBCtx, code string) error
 }
 
+var (
+	ErrInvitationAccepted  = errors.New("user has already set the password")
+	ErrNoPendingInvitation = errors.New("user has no pending invitation")
+)
+
 type userService struct {
-	userR repository.UserRepository
-	roleR repository.RoleRepository
-	otpR  repository.UserOTPRepository
+	userR       repository.UserRepository
+	roleR       repository.RoleRepository
+	otpR        repository.UserOTPRepository
+	invitationR repository.UserInvitationRepository
+	notifyS     NotificationService
 }
 
-func NewUserService(user repository.UserRepository, role repository.RoleRepository, otp repository.UserOTPRepository) *userService {
-	return &userService{user, role, otp}
+func NewUserService(user repository.UserRepository, role repository.RoleRepository, otp repository.UserOTPRepository, invitation repository.UserInvitationRepository, notify NotificationService) *userService {
+	return &userService{user, role, otp, invitation, notify}
 }
 
 func (s *userService) FindUser(dbctx db.DBCtx, code string) (model.User, error) {
@@ -63,8 +73,8 @@ func (s *userService) FindAllUser(dbctx db.DBCtx, c *fiber.Ctx) ([]model.User, i
 		listRoles := strings.Split(roles, ",")
 		if len(listRoles) > 0 {
 			for _, role := range listRoles {
-				if err := model.CheckValidRoleSlug(role); err != nil {
-					return userList, total, common.PaginateQueryOffset{}, err
+				if _, err := s.roleR.GetIDBySlug(dbctx, role); err != nil {
+					return userList, total, common.PaginateQueryOffset{}, fmt.Errorf(`invalid role %s`, role)
 				}
 			}
 			f.Roles = listRoles
@@ -90,23 +100,22 @@ func (s *userService) FindAllUser(dbctx db.DBCtx, c *fiber.Ctx) ([]model.User, i
 	return userList, total, pg, err
 }
 
-func (s *userService) CreateUser(dbctx db.DBCtx, req requests.UserCreateRequest, handlerBy, roleBy string) (model.User, error) {
+func (s *userService) CreateUser(dbctx db.DBCtx, req requests.UserCreateRequest, handlerBy string) (model.User, error) {
 	// Define data
 	var user model.User
 
-	// Check role by
-	if !common.CheckStringContains(roleBy, []string{model.ROLE_ADMIN}) {
-		return user, errors.New("invalid role access")
-	}
-
 	// Phone validation
 	_, _, validPhone := common.IsPhone(req.Phone)
 	if !validPhone {
 		return user, fmt.Errorf(`phone %s is invalid`, req.Phone)
 	}
 
-	// Hash password
-	passwordHash, err := bcrypt.GenerateFromPassword([]byte(model.DEFAULT_PASS), bcrypt.MinCost)
+	// Random password, the user must set its own password
+	password, err := utils.RandomPassword()
+	if err != nil {
+		return user, err
+	}
+	passwordHash, err := utils.HashPassword(password)
 	if err != nil {
 		return user, err
 	}
@@ -118,10 +127,12 @@ func (s *userService) CreateUser(dbctx db.DBCtx, req requests.UserCreateRequest,
 		Name:      req.Name,
 		Email:     req.Email,
 		Phone:     req.Phone,
-		Password:  string(passwordHash),
+		Password:  passwordHash,
 		Address:   sql.NullString{Valid: true, String: req.Address},
 		Img:       sql.NullString{Valid: true, String: req.Img},
 		CreatedBy: handlerBy,
+
+		MustChangePassword: true,
 	}
 
 	if len(req.RoleCode) <= 0 {
@@ -153,23 +164,35 @@ func (s *userService) UpdateUser(dbctx db.DBCtx, req requests.UserUpdateRequest,
 	var user model.User
 
 	// Check version
-	version, err := s.userR.GetVersionByCode(dbctx, code)
+	current, err := s.userR.GetByCode(dbctx, code)
 	if err != nil {
 		return user, err
 	}
-	if version != int32(req.Version) {
+	if current.Version != int32(req.Version) {
 		return user, errors.New("version is not match")
 	}
 
-	// Update role
+	// Phone validation
+	_, _, validPhone := common.IsPhone(req.Phone)
+	if !validPhone {
+		return user, fmt.Errorf(`phone %s is invalid`, req.Phone)
+	}
+
+	// Status is kept when not sent
+	status := current.Status
+	if req.Status != nil {
+		status = *req.Status
+	}
+
+	// Update user
 	user = model.User{
 		Code:        code,
 		Name:        req.Name,
 		Phone:       req.Phone,
 		Address:     sql.NullString{Valid: true, String: req.Address},
 		Img:         sql.NullString{Valid: true, String: req.Img},
-		Status:      *req.Status,
-		Version:     version + 1,
+		Status:      status,
+		Version:     current.Version + 1,
 		UpdatedDate: sql.NullTime{Valid: true, Time: time.Now().In(time.UTC)},
 		UpdatedBy:   sql.NullString{Valid: true, String: handlerBy},
 	}
@@ -186,3 +209,65 @@ func (s *userService) DeleteUser(dbctx db.DBCtx, handlerBy, code string) error {
 
 	return s.userR.Delete(dbctx, code, handlerBy)
 }
+
+// InviteUser send a single-use link to set the password, a new invitation replaces the pending one
+func (s *userService) InviteUser(dbctx db.DBCtx, user model.User, client model.ApiClient, handlerBy string) error {
+	_, err := s.invitationR.RevokeActiveByUserID(dbctx, user.ID)
+	if err != nil {
+		return err
+	}
+
+	// Store hash of invitation token
+	token, tokenHash, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+	_, err = s.invitationR.Insert(dbctx, model.UserInvitation{
+		UserID:      user.ID,
+		TokenHash:   tokenHash,
+		ExpiredDate: time.Now().In(time.UTC).Add(model.INVITATION_EXPIRED_TIME * time.Hour),
+		CreatedBy:   handlerBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Notify invitation
+	dataMail := utils.DataEmailToken{
+		Title:       "Invitation",
+		Description: "Please click the link",
+		ExpiredTime: model.INVITATION_EXPIRED_TIME,
+		TokenURL:    fmt.Sprintf("%s/auth/%s?token=%s", client.CallbackURL(), utils.MAIL_FOR_INVITE, token),
+	}
+
+	return s.notifyS.Notify(dbctx, utils.MAIL_FOR_INVITE, user, dataMail)
+}
+
+func (s *userService) ResendInvitation(dbctx db.DBCtx, code string, client model.ApiClient, handlerBy string) error {
+	user, err := s.FindUser(dbctx, code)
+	if err != nil {
+		return err
+	}
+	if !user.MustChangePassword {
+		return ErrInvitationAccepted
+	}
+
+	return s.InviteUser(dbctx, user, client, handlerBy)
+}
+
+func (s *userService) RevokeInvitation(dbctx db.DBCtx, code string) error {
+	user, err := s.FindUser(dbctx, code)
+	if err != nil {
+		return err
+	}
+
+	revoked, err := s.invitationR.RevokeActiveByUserID(dbctx, user.ID)
+	if err != nil {
+		return err
+	}
+	if revoked <= 0 {
+		return ErrNoPendingInvitation
+	}
+
+	return nil
+}
@@ -2,13 +2,26 @@ package config
 
 import (
 	"log"
+	"os"
+	"strconv"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	App      AppConfig
-	Database DatabaseConfig
+	App          AppConfig
+	Database     DatabaseConfig
+	JWT          JWTConfig
+	MFA          MFAConfig
+	Lockout      LockoutConfig
+	RateLimit    RateLimitConfig
+	Signature    SignatureConfig
+	OIDC         OIDCConfig
+	Password     PasswordPolicyConfig
+	Hash         PasswordHashConfig
+	Notifier     NotifierConfig
+	Notification NotificationConfig
+	Mail         MailConfig
 }
 
 func New() *Config {
@@ -18,7 +31,28 @@ func New() *Config {
 	}
 
 	return &Config{
-		App:      LoadAppConfig(),
-		Database: LoadDatabaseConfig(),
+		App:          LoadAppConfig(),
+		Database:     LoadDatabaseConfig(),
+		JWT:          LoadJWTConfig(),
+		MFA:          LoadMFAConfig(),
+		Lockout:      LoadLockoutConfig(),
+		RateLimit:    LoadRateLimitConfig(),
+		Signature:    LoadSignatureConfig(),
+		OIDC:         LoadOIDCConfig(),
+		Password:     LoadPasswordPolicyConfig(),
+		Hash:         LoadPasswordHashConfig(),
+		Notifier:     LoadNotifierConfig(),
+		Notification: LoadNotificationConfig(),
+		Mail:         LoadMailConfig(),
 	}
 }
+
+// getEnvInt read an integer env variable, fallback to def when empty or invalid
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+
+	return v
+}
@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type JWTConfig struct {
+	Secret      string
+	KeysDir     string
+	ActiveKeyID string
+	AccessTTL   time.Duration
+	RefreshTTL  time.Duration
+}
+
+func LoadJWTConfig() JWTConfig {
+	return JWTConfig{
+		Secret:      os.Getenv("APP_KEY"),
+		KeysDir:     os.Getenv("JWT_KEYS_DIR"),
+		ActiveKeyID: os.Getenv("JWT_ACTIVE_KID"),
+		AccessTTL:   time.Duration(getEnvInt("JWT_ACCESS_TTL", 15)) * time.Minute,
+		RefreshTTL:  time.Duration(getEnvInt("JWT_REFRESH_TTL", 720)) * time.Hour,
+	}
+}
@@ -0,0 +1,21 @@
+package config
+
+import "time"
+
+type LockoutConfig struct {
+	MaxAccountAttempts int
+	MaxIPAttempts      int
+	AttemptWindow      time.Duration
+	LockoutBase        time.Duration
+	LockoutMax         time.Duration
+}
+
+func LoadLockoutConfig() LockoutConfig {
+	return LockoutConfig{
+		MaxAccountAttempts: getEnvInt("LOCKOUT_MAX_ACCOUNT_ATTEMPTS", 5),
+		MaxIPAttempts:      getEnvInt("LOCKOUT_MAX_IP_ATTEMPTS", 20),
+		AttemptWindow:      time.Duration(getEnvInt("LOCKOUT_ATTEMPT_WINDOW", 15)) * time.Minute,
+		LockoutBase:        time.Duration(getEnvInt("LOCKOUT_BASE", 60)) * time.Second,
+		LockoutMax:         time.Duration(getEnvInt("LOCKOUT_MAX", 60)) * time.Minute,
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	MAIL_ENCRYPTION_SSL      = "ssl"
+	MAIL_ENCRYPTION_STARTTLS = "starttls"
+	MAIL_ENCRYPTION_NONE     = "none"
+)
+
+type MailConfig struct {
+	// Transport smtp, file (maildir of .eml in FileDir) or memory (kept in the process, for tests only)
+	Transport string
+	FileDir   string
+	// DevMailbox serves the mails of the file transport at /dev/mailbox, only when APP_DEBUG is true
+	DevMailbox bool
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	// Encryption ssl (implicit tls), starttls or none
+	Encryption  string
+	FromAddress string
+	FromName    string
+	// Timeout of dialing and of every message sent
+	Timeout time.Duration
+	// PoolSize idle connections kept open, reused until IdleTimeout
+	PoolSize    int
+	IdleTimeout time.Duration
+}
+
+func LoadMailConfig() MailConfig {
+	cfg := MailConfig{
+		Transport:   strings.ToLower(os.Getenv("MAIL_TRANSPORT")),
+		FileDir:     os.Getenv("MAIL_FILE_DIR"),
+		DevMailbox:  getEnvInt("MAIL_DEV_MAILBOX", 0) == 1 && os.Getenv("APP_DEBUG") == "true",
+		Host:        os.Getenv("MAIL_HOST"),
+		Port:        getEnvInt("MAIL_PORT", 587),
+		Username:    os.Getenv("MAIL_USERNAME"),
+		Password:    os.Getenv("MAIL_PASSWORD"),
+		Encryption:  strings.ToLower(os.Getenv("MAIL_ENCRYPTION")),
+		FromAddress: os.Getenv("MAIL_FROM_ADDRESS"),
+		FromName:    os.Getenv("MAIL_FROM_NAME"),
+		Timeout:     time.Duration(getEnvInt("MAIL_TIMEOUT", 10)) * time.Second,
+		PoolSize:    getEnvInt("MAIL_POOL_SIZE", 2),
+		IdleTimeout: time.Duration(getEnvInt("MAIL_IDLE_TIMEOUT", 30)) * time.Second,
+	}
+
+	if len(cfg.Transport) <= 0 {
+		cfg.Transport = "smtp"
+	}
+	if len(cfg.FileDir) <= 0 {
+		cfg.FileDir = "storage/mail"
+	}
+
+	// tls is the common name of starttls, without encryption the port tells
+	switch cfg.Encryption {
+	case "tls":
+		cfg.Encryption = MAIL_ENCRYPTION_STARTTLS
+	case "":
+		cfg.Encryption = MAIL_ENCRYPTION_STARTTLS
+		if cfg.Port == 465 {
+			cfg.Encryption = MAIL_ENCRYPTION_SSL
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,23 @@
+package config
+
+import "os"
+
+type MFAConfig struct {
+	Issuer    string
+	SecretKey string
+}
+
+func LoadMFAConfig() MFAConfig {
+	cfg := MFAConfig{
+		Issuer:    os.Getenv("MFA_ISSUER"),
+		SecretKey: os.Getenv("MFA_SECRET_KEY"),
+	}
+	if len(cfg.Issuer) <= 0 {
+		cfg.Issuer = os.Getenv("APP_NAME")
+	}
+	if len(cfg.SecretKey) <= 0 {
+		cfg.SecretKey = os.Getenv("APP_KEY")
+	}
+
+	return cfg
+}
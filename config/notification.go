@@ -0,0 +1,24 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type NotificationConfig struct {
+	// WebhookURL receives the notifications of the webhook channel, empty disables the channel
+	WebhookURL     string
+	WebhookSecret  string
+	WebhookTimeout time.Duration
+	// DispatchInterval how often the consumer queues the committed pending deliveries
+	DispatchInterval time.Duration
+}
+
+func LoadNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		WebhookURL:       os.Getenv("NOTIFICATION_WEBHOOK_URL"),
+		WebhookSecret:    os.Getenv("NOTIFICATION_WEBHOOK_SECRET"),
+		WebhookTimeout:   time.Duration(getEnvInt("NOTIFICATION_WEBHOOK_TIMEOUT", 10)) * time.Second,
+		DispatchInterval: time.Duration(getEnvInt("NOTIFICATION_DISPATCH_INTERVAL", 1)) * time.Second,
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// NotifierRoute provider and channel (sms or whatsapp) delivering messages to a phone
+type NotifierRoute struct {
+	Provider string
+	Channel  string
+}
+
+type NotifierTwilioConfig struct {
+	AccountSID   string
+	AuthToken    string
+	From         string
+	WhatsAppFrom string
+}
+
+type NotifierConfig struct {
+	// Routes by ISO3166 alpha-2 country code of the phone, Default for the others
+	Routes   map[string]NotifierRoute
+	Default  NotifierRoute
+	Timeout  time.Duration
+	FakeFile string
+	Twilio   NotifierTwilioConfig
+}
+
+func LoadNotifierConfig() NotifierConfig {
+	cfg := NotifierConfig{
+		Routes:   map[string]NotifierRoute{},
+		Default:  parseNotifierRoute(os.Getenv("NOTIFIER_DEFAULT_ROUTE")),
+		Timeout:  time.Duration(getEnvInt("NOTIFIER_TIMEOUT", 10)) * time.Second,
+		FakeFile: os.Getenv("NOTIFIER_FAKE_FILE"),
+		Twilio: NotifierTwilioConfig{
+			AccountSID:   os.Getenv("NOTIFIER_TWILIO_ACCOUNT_SID"),
+			AuthToken:    os.Getenv("NOTIFIER_TWILIO_AUTH_TOKEN"),
+			From:         os.Getenv("NOTIFIER_TWILIO_FROM"),
+			WhatsAppFrom: os.Getenv("NOTIFIER_TWILIO_WHATSAPP_FROM"),
+		},
+	}
+	if len(cfg.Default.Provider) <= 0 {
+		cfg.Default = NotifierRoute{Provider: "fake", Channel: "sms"}
+	}
+	if len(cfg.FakeFile) <= 0 {
+		cfg.FakeFile = "storage/notifier.log"
+	}
+
+	// NOTIFIER_ROUTES is a comma separated list of COUNTRY=provider:channel
+	for _, route := range strings.Split(os.Getenv("NOTIFIER_ROUTES"), ",") {
+		parts := strings.SplitN(strings.TrimSpace(route), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		cfg.Routes[strings.ToUpper(strings.TrimSpace(parts[0]))] = parseNotifierRoute(parts[1])
+	}
+
+	return cfg
+}
+
+// parseNotifierRoute read provider:channel, channel default to sms
+func parseNotifierRoute(value string) NotifierRoute {
+	parts := strings.SplitN(strings.ToLower(strings.TrimSpace(value)), ":", 2)
+	route := NotifierRoute{Provider: parts[0], Channel: "sms"}
+	if len(parts) == 2 && len(parts[1]) > 0 {
+		route.Channel = parts[1]
+	}
+
+	return route
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type OIDCProviderConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+}
+
+type OIDCConfig struct {
+	// Providers by name, enabled through OIDC_PROVIDERS
+	Providers map[string]OIDCProviderConfig
+}
+
+func LoadOIDCConfig() OIDCConfig {
+	cfg := OIDCConfig{Providers: map[string]OIDCProviderConfig{}}
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if len(name) <= 0 {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(name) + "_"
+		scopes := os.Getenv(prefix + "SCOPES")
+		if len(scopes) <= 0 {
+			scopes = "openid email profile"
+		}
+		cfg.Providers[name] = OIDCProviderConfig{
+			Issuer:       os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			RedirectURL:  os.Getenv(prefix + "REDIRECT_URL"),
+			Scopes:       strings.Fields(scopes),
+		}
+	}
+
+	return cfg
+}
@@ -0,0 +1,59 @@
+package config
+
+import "os"
+
+type PasswordPolicyConfig struct {
+	MinLength     int
+	MaxLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// Reject passwords containing the name or the email of the user
+	RejectSimilar bool
+	// Number of previous passwords which can not be used again, 0 disables the check
+	HistorySize int
+	// Breached password hash-prefix file, empty disables the check
+	BreachedFile string
+}
+
+func LoadPasswordPolicyConfig() PasswordPolicyConfig {
+	return PasswordPolicyConfig{
+		MinLength:     getEnvInt("PASSWORD_MIN_LENGTH", 8),
+		MaxLength:     getEnvInt("PASSWORD_MAX_LENGTH", 72),
+		RequireUpper:  getEnvInt("PASSWORD_REQUIRE_UPPER", 1) == 1,
+		RequireLower:  getEnvInt("PASSWORD_REQUIRE_LOWER", 1) == 1,
+		RequireDigit:  getEnvInt("PASSWORD_REQUIRE_DIGIT", 1) == 1,
+		RequireSymbol: getEnvInt("PASSWORD_REQUIRE_SYMBOL", 0) == 1,
+		RejectSimilar: getEnvInt("PASSWORD_REJECT_SIMILAR", 1) == 1,
+		HistorySize:   getEnvInt("PASSWORD_HISTORY_SIZE", 5),
+		BreachedFile:  os.Getenv("PASSWORD_BREACHED_FILE"),
+	}
+}
+
+type PasswordHashConfig struct {
+	// Algorithm of new hashes, argon2id or bcrypt, hashes of both are verified
+	Algorithm  string
+	BcryptCost int
+	// Argon2id iterations, memory in KiB, parallelism and key length in bytes
+	ArgonTime      int
+	ArgonMemory    int
+	ArgonThreads   int
+	ArgonKeyLength int
+}
+
+func LoadPasswordHashConfig() PasswordHashConfig {
+	algorithm := os.Getenv("PASSWORD_HASH_ALGORITHM")
+	if len(algorithm) <= 0 {
+		algorithm = "argon2id"
+	}
+
+	return PasswordHashConfig{
+		Algorithm:      algorithm,
+		BcryptCost:     getEnvInt("PASSWORD_BCRYPT_COST", 12),
+		ArgonTime:      getEnvInt("PASSWORD_ARGON_TIME", 3),
+		ArgonMemory:    getEnvInt("PASSWORD_ARGON_MEMORY", 64*1024),
+		ArgonThreads:   getEnvInt("PASSWORD_ARGON_THREADS", 2),
+		ArgonKeyLength: getEnvInt("PASSWORD_ARGON_KEY_LENGTH", 32),
+	}
+}
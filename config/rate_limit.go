@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	RATE_LIMIT_KEY_IP      = "ip"
+	RATE_LIMIT_KEY_USER    = "user"
+	RATE_LIMIT_KEY_CHANNEL = "channel"
+)
+
+// RateLimitRule allow Max requests per Window for every value of Key
+type RateLimitRule struct {
+	Max    int
+	Window time.Duration
+	Key    string
+}
+
+type RateLimitConfig struct {
+	Rules map[string]RateLimitRule
+}
+
+func LoadRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Rules: map[string]RateLimitRule{
+			"client":  loadRateLimitRule("CLIENT", 300, 60, RATE_LIMIT_KEY_IP),
+			"api":     loadRateLimitRule("API", 300, 60, RATE_LIMIT_KEY_IP),
+			"auth":    loadRateLimitRule("AUTH", 30, 60, RATE_LIMIT_KEY_IP),
+			"otp":     loadRateLimitRule("OTP", 5, 600, RATE_LIMIT_KEY_IP),
+			"private": loadRateLimitRule("PRIVATE", 120, 60, RATE_LIMIT_KEY_USER),
+		},
+	}
+}
+
+// loadRateLimitRule read RATE_LIMIT_<NAME>_MAX, _WINDOW (seconds) and _KEY
+func loadRateLimitRule(name string, max, window int, key string) RateLimitRule {
+	rule := RateLimitRule{
+		Max:    getEnvInt("RATE_LIMIT_"+name+"_MAX", max),
+		Window: time.Duration(getEnvInt("RATE_LIMIT_"+name+"_WINDOW", window)) * time.Second,
+		Key:    strings.ToLower(os.Getenv("RATE_LIMIT_" + name + "_KEY")),
+	}
+	if len(rule.Key) <= 0 {
+		rule.Key = key
+	}
+
+	return rule
+}
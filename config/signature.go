@@ -0,0 +1,22 @@
+package config
+
+import (
+	"os"
+	"time"
+)
+
+type SignatureConfig struct {
+	// Secrets of the request signature by client channel
+	Secrets   map[string]string
+	ClockSkew time.Duration
+}
+
+func LoadSignatureConfig() SignatureConfig {
+	return SignatureConfig{
+		Secrets: map[string]string{
+			"app": os.Getenv("SIGNATURE_SECRET_APP"),
+			"web": os.Getenv("SIGNATURE_SECRET_WEB"),
+		},
+		ClockSkew: time.Duration(getEnvInt("SIGNATURE_CLOCK_SKEW", 300)) * time.Second,
+	}
+}
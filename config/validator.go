@@ -14,6 +14,44 @@ const (
 	IDN = "id"
 )
 
+// Password policy translation keys, {0} is the field and {1} the policy parameter
+const (
+	PASSWORD_MIN_LENGTH = "password_min_length"
+	PASSWORD_MAX_LENGTH = "password_max_length"
+	PASSWORD_UPPER      = "password_upper"
+	PASSWORD_LOWER      = "password_lower"
+	PASSWORD_DIGIT      = "password_digit"
+	PASSWORD_SYMBOL     = "password_symbol"
+	PASSWORD_SIMILAR    = "password_similar"
+	PASSWORD_REUSED     = "password_reused"
+	PASSWORD_BREACHED   = "password_breached"
+)
+
+var PasswordPolicyMessages = map[string]map[string]string{
+	ENG: {
+		PASSWORD_MIN_LENGTH: "{0} must be at least {1} characters in length",
+		PASSWORD_MAX_LENGTH: "{0} must be a maximum of {1} characters in length",
+		PASSWORD_UPPER:      "{0} must contain an uppercase letter",
+		PASSWORD_LOWER:      "{0} must contain a lowercase letter",
+		PASSWORD_DIGIT:      "{0} must contain a digit",
+		PASSWORD_SYMBOL:     "{0} must contain a symbol",
+		PASSWORD_SIMILAR:    "{0} must not contain your name or email",
+		PASSWORD_REUSED:     "{0} must not be one of your last {1} passwords",
+		PASSWORD_BREACHED:   "{0} has appeared in a data breach, please choose another one",
+	},
+	IDN: {
+		PASSWORD_MIN_LENGTH: "panjang {0} minimal {1} karakter",
+		PASSWORD_MAX_LENGTH: "panjang {0} maksimal {1} karakter",
+		PASSWORD_UPPER:      "{0} harus mengandung huruf besar",
+		PASSWORD_LOWER:      "{0} harus mengandung huruf kecil",
+		PASSWORD_DIGIT:      "{0} harus mengandung angka",
+		PASSWORD_SYMBOL:     "{0} harus mengandung simbol",
+		PASSWORD_SIMILAR:    "{0} tidak boleh mengandung nama atau email anda",
+		PASSWORD_REUSED:     "{0} tidak boleh sama dengan {1} password terakhir anda",
+		PASSWORD_BREACHED:   "{0} pernah bocor dalam pelanggaran data, silakan pilih yang lain",
+	},
+}
+
 type Validator struct {
 	Driver     *validator.Validate
 	Uni        *ut.UniversalTranslator
@@ -32,6 +70,8 @@ func SetupValidator(c *AppConfig) *Validator {
 
 	_ = enTranslations.RegisterDefaultTranslations(validatorDriver, transEN)
 	_ = idTranslations.RegisterDefaultTranslations(validatorDriver, transID)
+	registerPasswordPolicyTranslations(transEN, PasswordPolicyMessages[ENG])
+	registerPasswordPolicyTranslations(transID, PasswordPolicyMessages[IDN])
 
 	var translator ut.Translator
 	switch c.Locale {
@@ -43,3 +83,9 @@ func SetupValidator(c *AppConfig) *Validator {
 
 	return &Validator{Driver: validatorDriver, Uni: uni, Translator: translator}
 }
+
+func registerPasswordPolicyTranslations(trans ut.Translator, messages map[string]string) {
+	for key, message := range messages {
+		_ = trans.Add(key, message, true)
+	}
+}
@@ -0,0 +1,10 @@
+CREATE TABLE public.refresh_tokens (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id) NOT NULL,
+	token_hash VARCHAR(64) UNIQUE NOT NULL,
+	family VARCHAR(50) NOT NULL,
+	expired_date TIMESTAMPTZ(0) NOT NULL,
+	revoked_date TIMESTAMPTZ(0) NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL
+);
+CREATE INDEX refresh_tokens_family_idx ON public.refresh_tokens (family);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.permissions;
\ No newline at end of file
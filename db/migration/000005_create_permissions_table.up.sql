@@ -0,0 +1,15 @@
+CREATE TABLE public.permissions (
+	id SERIAL PRIMARY KEY,
+	slug VARCHAR(50) UNIQUE NOT NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL
+);
+INSERT INTO public.permissions (slug, created_date) VALUES
+	('role:create', now()),
+	('role:read', now()),
+	('role:update', now()),
+	('role:delete', now()),
+	('user:create', now()),
+	('user:read', now()),
+	('user:update', now()),
+	('user:delete', now()),
+	('user:logout', now());
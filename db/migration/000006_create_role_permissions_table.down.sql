@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.role_permissions;
\ No newline at end of file
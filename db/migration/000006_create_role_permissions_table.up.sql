@@ -0,0 +1,9 @@
+CREATE TABLE public.role_permissions (
+	role_id INTEGER REFERENCES roles(id) NOT NULL,
+	permission_id INTEGER REFERENCES permissions(id) NOT NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL,
+	created_by VARCHAR(10) NOT NULL,
+	PRIMARY KEY (role_id, permission_id)
+);
+INSERT INTO public.role_permissions (role_id, permission_id, created_date, created_by)
+	SELECT r.id, p.id, now(), r.created_by FROM public.roles r CROSS JOIN public.permissions p WHERE r.slug = 'admin';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS public.user_mfas;
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE public.user_mfas (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER UNIQUE REFERENCES users(id) NOT NULL,
+	secret TEXT NOT NULL,
+	recovery_codes TEXT[] DEFAULT '{}' NOT NULL,
+	last_used_step BIGINT DEFAULT 0 NOT NULL,
+	"status" BOOLEAN DEFAULT false NOT NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL,
+	updated_date TIMESTAMPTZ(0) NULL
+);
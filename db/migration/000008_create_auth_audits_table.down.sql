@@ -0,0 +1,3 @@
+DELETE FROM public.role_permissions WHERE permission_id IN (SELECT id FROM public.permissions WHERE slug = 'user:unlock');
+DELETE FROM public.permissions WHERE slug = 'user:unlock';
+DROP TABLE IF EXISTS public.auth_audits;
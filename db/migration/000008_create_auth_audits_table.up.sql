@@ -0,0 +1,13 @@
+CREATE TABLE public.auth_audits (
+	id SERIAL PRIMARY KEY,
+	"event" VARCHAR(50) NOT NULL,
+	identifier VARCHAR(100) NOT NULL,
+	ip VARCHAR(50) NOT NULL,
+	detail TEXT NULL,
+	created_by VARCHAR(10) NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL
+);
+CREATE INDEX auth_audits_identifier_idx ON public.auth_audits (identifier);
+INSERT INTO public.permissions (slug, created_date) VALUES ('user:unlock', now());
+INSERT INTO public.role_permissions (role_id, permission_id, created_date, created_by)
+	SELECT r.id, p.id, now(), r.created_by FROM public.roles r CROSS JOIN public.permissions p WHERE r.slug = 'admin' AND p.slug = 'user:unlock';
@@ -0,0 +1,3 @@
+DELETE FROM public.role_permissions WHERE permission_id IN (SELECT id FROM public.permissions WHERE slug LIKE 'client:%');
+DELETE FROM public.permissions WHERE slug LIKE 'client:%';
+DROP TABLE IF EXISTS public.api_clients;
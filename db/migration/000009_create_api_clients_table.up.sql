@@ -0,0 +1,25 @@
+CREATE TABLE public.api_clients (
+	id SERIAL PRIMARY KEY,
+	code VARCHAR(10) UNIQUE NOT NULL,
+	"name" VARCHAR(50) NOT NULL,
+	client_id VARCHAR(64) UNIQUE NOT NULL,
+	secret_hash VARCHAR(64) NOT NULL,
+	channel VARCHAR(20) NOT NULL,
+	allowed_roles TEXT[] DEFAULT '{}' NOT NULL,
+	callback_urls TEXT[] DEFAULT '{}' NOT NULL,
+	"status" BOOLEAN DEFAULT false NOT NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL,
+	created_by VARCHAR(10) NOT NULL,
+	updated_date TIMESTAMPTZ(0) NULL,
+	updated_by VARCHAR(10) NULL,
+	deleted_date TIMESTAMPTZ(0) NULL,
+	deleted_by VARCHAR(10) NULL,
+	"version" INTEGER NOT NULL
+);
+INSERT INTO public.permissions (slug, created_date) VALUES
+	('client:create', now()),
+	('client:read', now()),
+	('client:update', now()),
+	('client:delete', now());
+INSERT INTO public.role_permissions (role_id, permission_id, created_date, created_by)
+	SELECT r.id, p.id, now(), r.created_by FROM public.roles r CROSS JOIN public.permissions p WHERE r.slug = 'admin' AND p.slug LIKE 'client:%';
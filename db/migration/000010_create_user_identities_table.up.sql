@@ -0,0 +1,11 @@
+CREATE TABLE public.user_identities (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id) NOT NULL,
+	provider VARCHAR(50) NOT NULL,
+	subject VARCHAR(255) NOT NULL,
+	email VARCHAR(100) NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL,
+	last_login_date TIMESTAMPTZ(0) NULL,
+	UNIQUE (provider, subject)
+);
+CREATE INDEX user_identities_user_id_idx ON public.user_identities (user_id);
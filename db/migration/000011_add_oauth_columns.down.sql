@@ -0,0 +1,3 @@
+ALTER TABLE public.refresh_tokens DROP COLUMN IF EXISTS scopes;
+ALTER TABLE public.refresh_tokens DROP COLUMN IF EXISTS client_id;
+ALTER TABLE public.api_clients DROP COLUMN IF EXISTS scopes;
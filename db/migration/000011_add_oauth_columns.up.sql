@@ -0,0 +1,3 @@
+ALTER TABLE public.api_clients ADD COLUMN scopes TEXT[] DEFAULT '{}' NOT NULL;
+ALTER TABLE public.refresh_tokens ADD COLUMN client_id VARCHAR(64) NULL;
+ALTER TABLE public.refresh_tokens ADD COLUMN scopes TEXT[] DEFAULT '{}' NOT NULL;
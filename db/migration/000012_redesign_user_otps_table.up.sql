@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS public.user_otps;
+CREATE TABLE public.user_otps (
+	id SERIAL PRIMARY KEY,
+	email VARCHAR(100) NOT NULL,
+	phone VARCHAR(15) NOT NULL,
+	purpose VARCHAR(50) NOT NULL,
+	otp_hash VARCHAR(64) NOT NULL,
+	salt VARCHAR(32) NOT NULL,
+	attempts_left SMALLINT NOT NULL,
+	expired_date TIMESTAMPTZ(0) NOT NULL,
+	verified_date TIMESTAMPTZ(0) NULL,
+	used_date TIMESTAMPTZ(0) NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL
+);
+CREATE INDEX user_otps_email_purpose_idx ON public.user_otps (email, purpose);
+CREATE INDEX user_otps_phone_purpose_idx ON public.user_otps (phone, purpose);
@@ -0,0 +1,7 @@
+CREATE TABLE public.password_histories (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id) NOT NULL,
+	password_hash VARCHAR(255) NOT NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL
+);
+CREATE INDEX password_histories_user_id_idx ON public.password_histories (user_id);
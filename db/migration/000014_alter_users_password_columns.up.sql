@@ -0,0 +1,2 @@
+ALTER TABLE public.users ALTER COLUMN "password" TYPE VARCHAR(255);
+ALTER TABLE public.users ADD COLUMN must_change_password BOOLEAN DEFAULT false NOT NULL;
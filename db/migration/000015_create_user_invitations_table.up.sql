@@ -0,0 +1,11 @@
+CREATE TABLE public.user_invitations (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id) NOT NULL,
+	token_hash VARCHAR(64) NOT NULL UNIQUE,
+	expired_date TIMESTAMPTZ(0) NOT NULL,
+	accepted_date TIMESTAMPTZ(0) NULL,
+	revoked_date TIMESTAMPTZ(0) NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL,
+	created_by VARCHAR(50) NOT NULL
+);
+CREATE INDEX user_invitations_user_id_idx ON public.user_invitations (user_id);
@@ -0,0 +1,12 @@
+CREATE TABLE public.user_sessions (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id) NOT NULL,
+	family VARCHAR(50) NOT NULL UNIQUE,
+	device_name VARCHAR(100) NOT NULL DEFAULT '',
+	user_agent VARCHAR(255) NOT NULL DEFAULT '',
+	ip VARCHAR(45) NOT NULL DEFAULT '',
+	created_date TIMESTAMPTZ(0) NOT NULL,
+	last_seen_date TIMESTAMPTZ(0) NOT NULL,
+	revoked_date TIMESTAMPTZ(0) NULL
+);
+CREATE INDEX user_sessions_user_id_idx ON public.user_sessions (user_id);
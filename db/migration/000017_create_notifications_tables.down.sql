@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS public.notification_preferences;
+DROP TABLE IF EXISTS public.notification_deliveries;
+DROP TABLE IF EXISTS public.notifications;
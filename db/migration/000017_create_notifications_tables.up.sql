@@ -0,0 +1,32 @@
+CREATE TABLE public.notifications (
+	id SERIAL PRIMARY KEY,
+	user_id INTEGER REFERENCES users(id) NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	title VARCHAR(255) NOT NULL,
+	body TEXT NOT NULL DEFAULT '',
+	inbox BOOLEAN NOT NULL DEFAULT FALSE,
+	read_date TIMESTAMPTZ(0) NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL
+);
+CREATE INDEX notifications_inbox_idx ON public.notifications (user_id, created_date) WHERE inbox;
+
+CREATE TABLE public.notification_deliveries (
+	id SERIAL PRIMARY KEY,
+	notification_id INTEGER REFERENCES notifications(id) ON DELETE CASCADE NOT NULL,
+	channel VARCHAR(20) NOT NULL,
+	recipient VARCHAR(255) NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	error TEXT NULL,
+	created_date TIMESTAMPTZ(0) NOT NULL,
+	sent_date TIMESTAMPTZ(0) NULL
+);
+CREATE INDEX notification_deliveries_notification_id_idx ON public.notification_deliveries (notification_id);
+
+CREATE TABLE public.notification_preferences (
+	user_id INTEGER REFERENCES users(id) NOT NULL,
+	type VARCHAR(50) NOT NULL,
+	channel VARCHAR(20) NOT NULL,
+	enabled BOOLEAN NOT NULL,
+	updated_date TIMESTAMPTZ(0) NOT NULL,
+	PRIMARY KEY (user_id, type, channel)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS notification_deliveries_pending_idx;
+ALTER TABLE public.notification_deliveries DROP COLUMN IF EXISTS payload;
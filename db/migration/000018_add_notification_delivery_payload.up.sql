@@ -0,0 +1,2 @@
+ALTER TABLE public.notification_deliveries ADD COLUMN payload JSONB NULL;
+CREATE INDEX notification_deliveries_pending_idx ON public.notification_deliveries (id) WHERE status = 'pending';
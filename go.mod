@@ -4,16 +4,16 @@ go 1.16
 
 require (
 	github.com/dongri/phonenumber v0.0.0-20220114222435-1b03252febb0
-	github.com/georgysavva/scany v0.3.0 // indirect
+	github.com/georgysavva/scany v0.3.0
 	github.com/go-playground/locales v0.14.0
 	github.com/go-playground/universal-translator v0.18.0
 	github.com/go-playground/validator/v10 v10.10.0
 	github.com/go-redis/redis/v8 v8.11.4
 	github.com/gofiber/fiber/v2 v2.24.0
-	github.com/gofiber/jwt/v2 v2.2.7
 	github.com/golang-jwt/jwt v3.2.2+incompatible
-	github.com/gosimple/slug v1.12.0 // indirect
+	github.com/gosimple/slug v1.12.0
 	github.com/jackc/pgconn v1.10.1
+	github.com/jackc/pgproto3/v2 v2.2.0
 	github.com/jackc/pgx/v4 v4.14.1
 	github.com/joho/godotenv v1.4.0
 	github.com/streadway/amqp v1.0.0
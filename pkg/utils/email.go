@@ -2,29 +2,64 @@ package utils
 
 import (
 	"bytes"
-	"fiber-starter/config"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"fiber-starter/pkg/common"
 	"fmt"
+	"html"
 	"html/template"
 	"log"
-	"net/smtp"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
-	"sync"
+	textTemplate "text/template"
+	"time"
 )
 
 type MailRequest struct {
-	to      []string
-	subject string
-	body    string
+	to          []string
+	subject     string
+	body        string
+	text        string
+	attachments []MailAttachment
 }
 
+// MailData mail queued to CMDQueueSendMail before notifications
 type MailData struct {
 	Receivers []string
 	Usage     string
 	Data      interface{}
 }
 
+// MailAttachment file attached to a mail, sent by reference (Path read by the consumer) or by Content,
+// which is base64 in the queue payload. Attachments with a ContentID are inline, referenced as cid:ContentID
+type MailAttachment struct {
+	Filename    string
+	ContentType string
+	Path        string
+	Content     []byte
+	ContentID   string
+}
+
+// MailMessage message built as MIME, the text alternative is derived from the html when empty
+type MailMessage struct {
+	FromName    string
+	From        string
+	To          string
+	Subject     string
+	HTML        string
+	Text        string
+	Attachments []MailAttachment
+	Date        time.Time
+}
+
 type DataEmailToken struct {
 	TokenURL     string
 	ExpiredTime  int
@@ -33,19 +68,39 @@ type DataEmailToken struct {
 	Title        string
 }
 
+// DataAccountNotice data of the notices sent when an account detail is changed
+type DataAccountNotice struct {
+	Name   string
+	Detail string
+	Date   string
+}
+
 const (
 	MAIL_TEMPLATE_PATH      = "public/templates/"
 	MAIL_FOR_USERACTIVATION = "user-activation"
 	MAIL_FOR_RESETPASS      = "reset-password"
-	MAIL_MIME               = "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	MAIL_FOR_LOGIN          = "login"
+	MAIL_FOR_INVITE         = "invite"
+	MAIL_FOR_CHANGEEMAIL    = "change-email"
+	MAIL_FOR_CHANGEPHONE    = "change-phone"
+	MAIL_FOR_PASSWORDCHANGE = "password-changed"
+	MAIL_FOR_EMAILCHANGE    = "email-changed"
+	MAIL_FOR_PHONECHANGE    = "phone-changed"
 )
 
 var mailSubject = map[string]string{
 	MAIL_FOR_USERACTIVATION: "[sample] User Activation",
 	MAIL_FOR_RESETPASS:      "[sample] Reset Password",
+	MAIL_FOR_LOGIN:          "[sample] Login",
+	MAIL_FOR_INVITE:         "[sample] Invitation",
+	MAIL_FOR_CHANGEEMAIL:    "[sample] Change Email",
+	MAIL_FOR_CHANGEPHONE:    "[sample] Change Phone",
+	MAIL_FOR_PASSWORDCHANGE: "[sample] Password Changed",
+	MAIL_FOR_EMAILCHANGE:    "[sample] Email Changed",
+	MAIL_FOR_PHONECHANGE:    "[sample] Phone Changed",
 }
 
-var ListUsedFor = []string{MAIL_FOR_USERACTIVATION, MAIL_FOR_RESETPASS}
+var ListUsedFor = []string{MAIL_FOR_USERACTIVATION, MAIL_FOR_RESETPASS, MAIL_FOR_LOGIN}
 
 func (r *MailRequest) parseTemplate(fileName string, data interface{}) error {
 	t, err := template.ParseFiles(fileName)
@@ -60,51 +115,43 @@ func (r *MailRequest) parseTemplate(fileName string, data interface{}) error {
 	return nil
 }
 
-func (r *MailRequest) sendMailAsync(ch chan<- bool, wg *sync.WaitGroup) {
-	defer wg.Done()
-
-	sent := true
-	mailHost := os.Getenv("MAIL_HOST")
-	mailFromAddress := os.Getenv("MAIL_FROM_ADDRESS")
-	mailUsername := os.Getenv("MAIL_USERNAME")
-	mailPassword := os.Getenv("MAIL_PASSWORD")
-
-	for _, to := range r.to {
-		email := []string{to}
-		SMTP := fmt.Sprintf("%s:%d", mailHost, 587)
-		body := "To: " + to + "\r\nFrom: " + mailFromAddress + "\r\nSubject: " + r.subject + "\r\n" + MAIL_MIME + "\r\n" + r.body
-
-		if err := smtp.SendMail(SMTP, smtp.PlainAuth("", mailUsername, mailPassword, mailHost), mailFromAddress, email, []byte(body)); err != nil {
-			fmt.Println(err)
-			sent = false
-		}
+// parseTextTemplate render the plain text template of the mail when it has one
+func (r *MailRequest) parseTextTemplate(fileName string, data interface{}) error {
+	if _, err := os.Stat(fileName); os.IsNotExist(err) {
+		return nil
 	}
-
-	ch <- bool(sent)
+	t, err := textTemplate.ParseFiles(fileName)
+	if err != nil {
+		return err
+	}
+	buffer := new(bytes.Buffer)
+	if err = t.Execute(buffer, data); err != nil {
+		return err
+	}
+	r.text = buffer.String()
+	return nil
 }
 
-func (r *MailRequest) sendMail() bool {
-	// make a channel
-	ch := make(chan bool)
-	var wg sync.WaitGroup
-
-	// do async request with channel
-	wg.Add(1)
-	go r.sendMailAsync(ch, &wg)
-
-	// close the channel in the background
-	go func() {
-		wg.Wait()
-		close(ch)
-	}()
+// sendMail send the mail to every recipient through the transport
+func (r *MailRequest) sendMail() MailResults {
+	msg := MailMessage{
+		FromName:    mailConfig.FromName,
+		From:        mailConfig.FromAddress,
+		Subject:     r.subject,
+		HTML:        r.body,
+		Text:        r.text,
+		Attachments: r.attachments,
+	}
 
-	// read from channel as they come in until its closed
-	var sent bool
-	for res := range ch {
-		sent = res
+	if mailTransport == nil {
+		return failedMailResults(r.to, errMailNotConfigured)
 	}
 
-	return sent
+	return mailTransport.Send(msg.From, r.to, func(to string) ([]byte, error) {
+		msg.To = to
+		msg.Date = time.Now()
+		return msg.Bytes()
+	})
 }
 
 func NewRequestSendMail(to []string, usedFor string) (*MailRequest, error) {
@@ -116,39 +163,60 @@ func NewRequestSendMail(to []string, usedFor string) (*MailRequest, error) {
 	}
 
 	mailReq.to = to
-	mailReq.subject = mailSubject[usedFor]
+	mailReq.subject = mailSubjectOf(usedFor)
 
 	return mailReq, nil
 }
 
-func (r *MailRequest) Send(usedFor string, items interface{}) {
+// Send render the templates of the mail and send it with the attachments, answers the result of every recipient
+func (r *MailRequest) Send(usedFor string, items interface{}, attachments ...MailAttachment) (MailResults, error) {
 	templateName := fmt.Sprintf("%s/%s.html", MAIL_TEMPLATE_PATH, usedFor)
 
 	err := r.parseTemplate(templateName, items)
 	if err != nil {
-		log.Fatal(err)
+		return nil, err
+	}
+	err = r.parseTextTemplate(fmt.Sprintf("%s/%s.txt", MAIL_TEMPLATE_PATH, usedFor), items)
+	if err != nil {
+		return nil, err
 	}
 
-	if ok := r.sendMail(); ok {
-		log.Printf("Email has been sent to %s\n", r.to)
-	} else {
-		log.Printf("Failed to send the email to %s\n", r.to)
+	// Attachments by reference are read once for every recipient
+	for _, attachment := range attachments {
+		if len(attachment.Content) <= 0 && len(attachment.Path) > 0 {
+			attachment.Content, err = os.ReadFile(attachment.Path)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if len(attachment.Filename) <= 0 {
+			attachment.Filename = filepath.Base(attachment.Path)
+		}
+		r.attachments = append(r.attachments, attachment)
 	}
-}
 
-func SetMailData(receivers []string, usage string, data interface{}) MailData {
-	return MailData{
-		Receivers: receivers,
-		Usage:     usage,
-		Data:      data,
+	results := r.sendMail()
+	for _, result := range results {
+		if result.Err == nil {
+			log.Printf("Email has been sent to %s\n", result.To)
+		}
 	}
+
+	return results, results.Err()
 }
 
-func PushQueueMail(config *config.RabbitMQ, qName string, data MailData) error {
-	queue := QueueRabbitMQ{RabbitMQ: config, Data: data}
-	err := PushQueueToRabbitMQ(queue, qName)
+// mailSubjectOf the subject of the usage, types without one are titled from their name
+func mailSubjectOf(usedFor string) string {
+	if subject, ok := mailSubject[usedFor]; ok {
+		return subject
+	}
 
-	return err
+	words := strings.Fields(strings.ReplaceAll(usedFor, "-", " "))
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+
+	return "[sample] " + strings.Join(words, " ")
 }
 
 func CheckValidUsedFor(used string) error {
@@ -161,3 +229,179 @@ func CheckValidUsedFor(used string) error {
 
 	return err
 }
+
+// mailPart a MIME part and its header
+type mailPart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// Bytes build the MIME message, the text and html are a multipart/alternative, wrapped with the inline
+// images in a multipart/related and with the other attachments in a multipart/mixed when there are any
+func (m MailMessage) Bytes() ([]byte, error) {
+	var inline, attached []mailPart
+	for _, attachment := range m.Attachments {
+		if len(attachment.ContentID) > 0 {
+			inline = append(inline, attachmentPart(attachment, "inline"))
+		} else {
+			attached = append(attached, attachmentPart(attachment, "attachment"))
+		}
+	}
+
+	text := m.Text
+	if len(text) <= 0 {
+		text = htmlToText(m.HTML)
+	}
+	content, err := multipartOf("alternative", []mailPart{textPart("text/plain", text), textPart("text/html", m.HTML)})
+	if err != nil {
+		return nil, err
+	}
+	if len(inline) > 0 {
+		content, err = multipartOf("related", append([]mailPart{content}, inline...))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(attached) > 0 {
+		content, err = multipartOf("mixed", append([]mailPart{content}, attached...))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Headers are RFC 2047 encoded, net/mail does it for the display names
+	from := mail.Address{Name: m.FromName, Address: m.From}
+	to := mail.Address{Address: m.To}
+	buffer := new(bytes.Buffer)
+	writeMailHeader(buffer, "From", from.String())
+	writeMailHeader(buffer, "To", to.String())
+	writeMailHeader(buffer, "Subject", mime.QEncoding.Encode("utf-8", stripHeaderBreaks(m.Subject)))
+	writeMailHeader(buffer, "Date", m.Date.Format(time.RFC1123Z))
+	writeMailHeader(buffer, "Message-ID", mailMessageID(m.From))
+	writeMailHeader(buffer, "MIME-Version", "1.0")
+	for key := range content.header {
+		writeMailHeader(buffer, key, content.header.Get(key))
+	}
+	buffer.WriteString("\r\n")
+	buffer.Write(content.body)
+
+	return buffer.Bytes(), nil
+}
+
+// multipartOf wrap the parts in a multipart of the subtype
+func multipartOf(subtype string, parts []mailPart) (mailPart, error) {
+	buffer := new(bytes.Buffer)
+	w := multipart.NewWriter(buffer)
+	for _, part := range parts {
+		pw, err := w.CreatePart(part.header)
+		if err != nil {
+			return mailPart{}, err
+		}
+		if _, err = pw.Write(part.body); err != nil {
+			return mailPart{}, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return mailPart{}, err
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType("multipart/"+subtype, map[string]string{"boundary": w.Boundary()}))
+
+	return mailPart{header, buffer.Bytes()}, nil
+}
+
+// textPart utf-8 text encoded as quoted-printable
+func textPart(contentType, text string) mailPart {
+	buffer := new(bytes.Buffer)
+	w := quotedprintable.NewWriter(buffer)
+	w.Write([]byte(text))
+	w.Close()
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", mime.FormatMediaType(contentType, map[string]string{"charset": "utf-8"}))
+	header.Set("Content-Transfer-Encoding", "quoted-printable")
+
+	return mailPart{header, buffer.Bytes()}
+}
+
+// attachmentPart file encoded as base64 in lines of 76 characters
+func attachmentPart(attachment MailAttachment, disposition string) mailPart {
+	contentType := attachment.ContentType
+	if len(contentType) <= 0 {
+		contentType = mime.TypeByExtension(filepath.Ext(attachment.Filename))
+	}
+	if len(contentType) <= 0 {
+		contentType = "application/octet-stream"
+	}
+	filename := stripHeaderBreaks(attachment.Filename)
+
+	// Parameters are quoted, or RFC 2231 encoded when not ascii
+	contentTypeHeader := mime.FormatMediaType(contentType, map[string]string{"name": filename})
+	if len(contentTypeHeader) <= 0 {
+		contentTypeHeader = mime.FormatMediaType("application/octet-stream", map[string]string{"name": filename})
+	}
+
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", contentTypeHeader)
+	header.Set("Content-Transfer-Encoding", "base64")
+	header.Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": filename}))
+	if len(attachment.ContentID) > 0 {
+		header.Set("Content-ID", "<"+stripHeaderBreaks(attachment.ContentID)+">")
+	}
+
+	encoded := base64.StdEncoding.EncodeToString(attachment.Content)
+	buffer := new(bytes.Buffer)
+	for len(encoded) > 76 {
+		buffer.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buffer.WriteString(encoded + "\r\n")
+
+	return mailPart{header, buffer.Bytes()}
+}
+
+func writeMailHeader(buffer *bytes.Buffer, key, value string) {
+	fmt.Fprintf(buffer, "%s: %s\r\n", key, value)
+}
+
+// mailMessageID unique id of the message at the domain of the sender
+func mailMessageID(from string) string {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 {
+		domain = from[at+1:]
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(b), domain)
+}
+
+// stripHeaderBreaks keep a header value on one line
+func stripHeaderBreaks(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
+
+var (
+	htmlDropped = regexp.MustCompile(`(?is)<(head|style|script)[^>]*>.*?</(head|style|script)>`)
+	htmlBreaks  = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|tr|h[1-6]|li)>`)
+	htmlLinks   = regexp.MustCompile(`(?is)<a\s[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	htmlTags    = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLines  = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText plain text of the html for mail clients without html, links keep their url
+func htmlToText(body string) string {
+	body = htmlDropped.ReplaceAllString(body, "")
+	body = htmlLinks.ReplaceAllString(body, "$2 ($1)")
+	body = htmlBreaks.ReplaceAllString(body, "\n")
+	body = htmlTags.ReplaceAllString(body, "")
+	body = html.UnescapeString(body)
+
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
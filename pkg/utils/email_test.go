@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMailPart leaf part of a message, quoted-printable is decoded by the multipart reader
+type testMailPart struct {
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// readMailParts read the leaf parts of the message by content type, attachments by filename
+func readMailParts(t *testing.T, r io.Reader, header mail.Header) map[string]testMailPart {
+	parts := map[string]testMailPart{}
+
+	var walk func(r io.Reader, contentType string)
+	walk = func(r io.Reader, contentType string) {
+		mediaType, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatalf("content type %q: %v", contentType, err)
+		}
+		if !strings.HasPrefix(mediaType, "multipart/") {
+			return
+		}
+
+		mr := multipart.NewReader(r, params["boundary"])
+		for {
+			part, err := mr.NextPart()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			partType := part.Header.Get("Content-Type")
+			if strings.HasPrefix(partType, "multipart/") {
+				walk(part, partType)
+				continue
+			}
+
+			key, _, _ := mime.ParseMediaType(partType)
+			if _, disposition, err := mime.ParseMediaType(part.Header.Get("Content-Disposition")); err == nil {
+				if filename := disposition["filename"]; len(filename) > 0 {
+					key = filename
+				}
+			}
+			body, err := ioutil.ReadAll(part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			parts[key] = testMailPart{part.Header, body}
+		}
+	}
+	walk(r, header.Get("Content-Type"))
+
+	return parts
+}
+
+func TestMailMessageBytesRoundTrip(t *testing.T) {
+	message := MailMessage{
+		FromName: "Sample Ñoreply",
+		From:     "noreply@sample.com",
+		To:       "user@sample.com",
+		Subject:  "Café\r\nBcc: injected@sample.com",
+		HTML:     `<p>Hello <a href="https://sample.com/verify">verify</a></p><img src="cid:logo">`,
+		Text:     "Hello, verify at https://sample.com/verify with a line longer than the seventy six characters of quoted printable",
+		Attachments: []MailAttachment{
+			{Filename: "résumé 2024.pdf", ContentType: "application/pdf", Content: bytes.Repeat([]byte("%PDF"), 40)},
+			{Filename: `quote".txt`, Content: []byte("plain")},
+			{Filename: "logo.png", ContentType: "image/png", Content: []byte{0x89, 'P', 'N', 'G'}, ContentID: "logo"},
+		},
+		Date: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+
+	raw, err := message.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Headers
+	decoder := new(mime.WordDecoder)
+	subject, err := decoder.DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil || subject != "CaféBcc: injected@sample.com" {
+		t.Errorf("subject %q, err %v", subject, err)
+	}
+	if len(msg.Header.Get("Bcc")) > 0 {
+		t.Error("header is injected through the subject")
+	}
+	from, err := msg.Header.AddressList("From")
+	if err != nil || from[0].Name != "Sample Ñoreply" || from[0].Address != "noreply@sample.com" {
+		t.Errorf("from %v, err %v", from, err)
+	}
+	if date, err := msg.Header.Date(); err != nil || !date.Equal(message.Date) {
+		t.Errorf("date %v, err %v", date, err)
+	}
+	if msg.Header.Get("MIME-Version") != "1.0" || len(msg.Header.Get("Message-ID")) <= 0 {
+		t.Errorf("headers %v", msg.Header)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(msg.Header.Get("Content-Type")); mediaType != "multipart/mixed" {
+		t.Errorf("content type %s, want multipart/mixed", mediaType)
+	}
+
+	parts := readMailParts(t, msg.Body, msg.Header)
+
+	// Text alternatives
+	for contentType, want := range map[string]string{"text/plain": message.Text, "text/html": message.HTML} {
+		part, ok := parts[contentType]
+		if !ok {
+			t.Errorf("missing %s part", contentType)
+			continue
+		}
+		if string(part.body) != want {
+			t.Errorf("%s %q", contentType, part.body)
+		}
+	}
+	if !bytes.Contains(raw, []byte("Content-Transfer-Encoding: quoted-printable")) {
+		t.Error("text is not quoted-printable")
+	}
+
+	// Attachments keep their name and content
+	for _, attachment := range message.Attachments {
+		part, ok := parts[attachment.Filename]
+		if !ok {
+			t.Errorf("missing attachment %q", attachment.Filename)
+			continue
+		}
+		content, err := base64.StdEncoding.DecodeString(string(part.body))
+		if err != nil || !bytes.Equal(content, attachment.Content) {
+			t.Errorf("attachment %q content %q, err %v", attachment.Filename, content, err)
+		}
+		_, params, err := mime.ParseMediaType(part.header.Get("Content-Type"))
+		if err != nil || params["name"] != attachment.Filename {
+			t.Errorf("attachment %q content type %q", attachment.Filename, part.header.Get("Content-Type"))
+		}
+	}
+
+	// Inline image is referenced by its content id
+	logo := parts["logo.png"]
+	if disposition, _, _ := mime.ParseMediaType(logo.header.Get("Content-Disposition")); disposition != "inline" || logo.header.Get("Content-ID") != "<logo>" {
+		t.Errorf("inline image headers %v", logo.header)
+	}
+	if pdf := parts["résumé 2024.pdf"]; !strings.Contains(pdf.header.Get("Content-Disposition"), "filename*=utf-8''") {
+		t.Errorf("non ascii filename is not RFC 2231 encoded: %s", pdf.header.Get("Content-Disposition"))
+	}
+}
+
+func TestMailMessageBytesWithoutAttachments(t *testing.T) {
+	raw, err := MailMessage{From: "noreply@sample.com", To: "user@sample.com", Subject: "Login", HTML: "<p>Your code<br>is <b>123456</b></p>"}.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaType, _, _ := mime.ParseMediaType(msg.Header.Get("Content-Type")); mediaType != "multipart/alternative" {
+		t.Errorf("content type %s, want multipart/alternative", mediaType)
+	}
+
+	// Text derived from the html
+	parts := readMailParts(t, msg.Body, msg.Header)
+	text := parts["text/plain"].body
+	if !strings.Contains(string(text), "Your code") || !strings.Contains(string(text), "is 123456") || strings.Contains(string(text), "<") {
+		t.Errorf("text %q", text)
+	}
+}
@@ -0,0 +1,210 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fiber-starter/config"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+
+	argonSaltLength   = 16
+	argonMinTime      = 1
+	argonMinMemory    = 1024 // KiB
+	argonMaxThreads   = 255
+	argonMinKeyLength = 16
+	passwordAlphabet  = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789!@#$%^&*-_=+"
+)
+
+var ErrUnknownPasswordHash = errors.New("unknown password hash format")
+
+// Hasher hashes passwords and verifies the hashes of its format.
+type Hasher interface {
+	Hash(password string) (string, error)
+	Verify(hash, password string) (bool, error)
+	// Identify check the hash has the format of the hasher
+	Identify(hash string) bool
+	// NeedsRehash check the hash parameters are weaker than the configured ones
+	NeedsRehash(hash string) bool
+}
+
+var (
+	// defaultHasher hashes new passwords, every hasher verifies
+	defaultHasher Hasher
+	hashers       []Hasher
+)
+
+// SetupHasher func to set the hashers and the default one of new passwords.
+func SetupHasher(cfg config.PasswordHashConfig) error {
+	if cfg.ArgonTime < argonMinTime {
+		return fmt.Errorf(`argon2id time %d is lower than %d`, cfg.ArgonTime, argonMinTime)
+	}
+	if cfg.ArgonThreads < 1 || cfg.ArgonThreads > argonMaxThreads {
+		return fmt.Errorf(`argon2id threads %d is not between 1 and %d`, cfg.ArgonThreads, argonMaxThreads)
+	}
+	if cfg.ArgonMemory < argonMinMemory || cfg.ArgonMemory < 8*cfg.ArgonThreads {
+		return fmt.Errorf(`argon2id memory %d KiB is lower than %d KiB or 8 KiB per thread`, cfg.ArgonMemory, argonMinMemory)
+	}
+	if cfg.ArgonKeyLength < argonMinKeyLength {
+		return fmt.Errorf(`argon2id key length %d is lower than %d`, cfg.ArgonKeyLength, argonMinKeyLength)
+	}
+
+	argon := &argon2idHasher{
+		time:      uint32(cfg.ArgonTime),
+		memory:    uint32(cfg.ArgonMemory),
+		threads:   uint8(cfg.ArgonThreads),
+		keyLength: uint32(cfg.ArgonKeyLength),
+	}
+	bcryptH := &bcryptHasher{cost: cfg.BcryptCost}
+	if bcryptH.cost < bcrypt.MinCost || bcryptH.cost > bcrypt.MaxCost {
+		return fmt.Errorf(`bcrypt cost %d is invalid`, cfg.BcryptCost)
+	}
+	hashers = []Hasher{argon, bcryptH}
+
+	switch cfg.Algorithm {
+	case HashArgon2id:
+		defaultHasher = argon
+	case HashBcrypt:
+		defaultHasher = bcryptH
+	default:
+		return fmt.Errorf(`password hash algorithm %s is not supported`, cfg.Algorithm)
+	}
+
+	return nil
+}
+
+// HashPassword func to hash a password with the default hasher.
+func HashPassword(password string) (string, error) {
+	return defaultHasher.Hash(password)
+}
+
+// VerifyPassword func to compare a password with its hash, rehash tells the hash should be
+// replaced because it has another algorithm or weaker parameters than the default hasher.
+func VerifyPassword(hash, password string) (match bool, rehash bool, err error) {
+	for _, hasher := range hashers {
+		if !hasher.Identify(hash) {
+			continue
+		}
+
+		match, err = hasher.Verify(hash, password)
+		if err != nil || !match {
+			return false, false, err
+		}
+
+		return true, hasher != defaultHasher || hasher.NeedsRehash(hash), nil
+	}
+
+	return false, false, ErrUnknownPasswordHash
+}
+
+// RandomPassword func to generate a password nobody knows, for users who set theirs later.
+func RandomPassword() (string, error) {
+	return RandomString(32, passwordAlphabet)
+}
+
+// argon2idHasher encodes hashes as $argon2id$v=19$m=65536,t=3,p=2$salt$key
+type argon2idHasher struct {
+	time      uint32
+	memory    uint32
+	threads   uint8
+	keyLength uint32
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, argonSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, h.time, h.memory, h.threads, h.keyLength)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, h.memory, h.time, h.threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+func (h *argon2idHasher) Verify(hash, password string) (bool, error) {
+	params, salt, key, err := h.decode(hash)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.time, params.memory, params.threads, uint32(len(key)))
+
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (h *argon2idHasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$argon2id$")
+}
+
+func (h *argon2idHasher) NeedsRehash(hash string) bool {
+	params, _, key, err := h.decode(hash)
+	if err != nil {
+		return true
+	}
+
+	return params.time < h.time || params.memory < h.memory || params.threads < h.threads || uint32(len(key)) < h.keyLength
+}
+
+// decode split the encoded hash into its parameters, salt and key
+func (h *argon2idHasher) decode(hash string) (argon2idHasher, []byte, []byte, error) {
+	var params argon2idHasher
+	var version int
+
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.time, &params.threads); err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrUnknownPasswordHash
+	}
+
+	return params, salt, key, nil
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+
+	return string(hash), err
+}
+
+func (h *bcryptHasher) Verify(hash, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+func (h *bcryptHasher) Identify(hash string) bool {
+	return strings.HasPrefix(hash, "$2")
+}
+
+func (h *bcryptHasher) NeedsRehash(hash string) bool {
+	cost, err := bcrypt.Cost([]byte(hash))
+
+	return err != nil || cost < h.cost
+}
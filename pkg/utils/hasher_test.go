@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"errors"
+	"fiber-starter/config"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testHashConfig parameters kept low so the tests are fast
+func testHashConfig(algorithm string) config.PasswordHashConfig {
+	return config.PasswordHashConfig{
+		Algorithm:      algorithm,
+		BcryptCost:     bcrypt.MinCost + 1,
+		ArgonTime:      2,
+		ArgonMemory:    1024,
+		ArgonThreads:   1,
+		ArgonKeyLength: 32,
+	}
+}
+
+func setupTestHasher(t *testing.T, cfg config.PasswordHashConfig) {
+	if err := SetupHasher(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArgon2idHashVerify(t *testing.T) {
+	setupTestHasher(t, testHashConfig(HashArgon2id))
+
+	hash, err := HashPassword("Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=2,p=1$") {
+		t.Errorf("hash %s", hash)
+	}
+
+	match, rehash, err := VerifyPassword(hash, "Secret-123")
+	if err != nil || !match || rehash {
+		t.Errorf("match %v, rehash %v, err %v", match, rehash, err)
+	}
+
+	match, rehash, err = VerifyPassword(hash, "Secret-124")
+	if err != nil || match || rehash {
+		t.Errorf("wrong password: match %v, rehash %v, err %v", match, rehash, err)
+	}
+
+	// Salted, the same password never has the same hash
+	other, _ := HashPassword("Secret-123")
+	if other == hash {
+		t.Error("hashes of the same password are equal")
+	}
+}
+
+func TestArgon2idRehashWeakerParams(t *testing.T) {
+	weak := testHashConfig(HashArgon2id)
+	weak.ArgonTime = 1
+	setupTestHasher(t, weak)
+	hash, err := HashPassword("Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setupTestHasher(t, testHashConfig(HashArgon2id))
+	match, rehash, err := VerifyPassword(hash, "Secret-123")
+	if err != nil || !match || !rehash {
+		t.Errorf("match %v, rehash %v, err %v", match, rehash, err)
+	}
+}
+
+func TestBcryptVerifiedAndRehashedToArgon2id(t *testing.T) {
+	setupTestHasher(t, testHashConfig(HashArgon2id))
+
+	legacy, err := bcrypt.GenerateFromPassword([]byte("Secret-123"), bcrypt.MinCost+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	match, rehash, err := VerifyPassword(string(legacy), "Secret-123")
+	if err != nil || !match || !rehash {
+		t.Errorf("match %v, rehash %v, err %v", match, rehash, err)
+	}
+
+	match, rehash, err = VerifyPassword(string(legacy), "Secret-124")
+	if err != nil || match || rehash {
+		t.Errorf("wrong password: match %v, rehash %v, err %v", match, rehash, err)
+	}
+}
+
+func TestBcryptRehashLowerCost(t *testing.T) {
+	setupTestHasher(t, testHashConfig(HashBcrypt))
+
+	hash, err := HashPassword("Secret-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, rehash, err := VerifyPassword(hash, "Secret-123")
+	if err != nil || !match || rehash {
+		t.Errorf("match %v, rehash %v, err %v", match, rehash, err)
+	}
+
+	lower, err := bcrypt.GenerateFromPassword([]byte("Secret-123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, rehash, err = VerifyPassword(string(lower), "Secret-123")
+	if err != nil || !match || !rehash {
+		t.Errorf("lower cost: match %v, rehash %v, err %v", match, rehash, err)
+	}
+}
+
+func TestVerifyPasswordUnknownHash(t *testing.T) {
+	setupTestHasher(t, testHashConfig(HashArgon2id))
+
+	for _, hash := range []string{"", "plain", "$argon2i$v=19$m=1024,t=2,p=1$c2FsdA$a2V5"} {
+		if _, _, err := VerifyPassword(hash, "Secret-123"); !errors.Is(err, ErrUnknownPasswordHash) {
+			t.Errorf("hash %q: err %v", hash, err)
+		}
+	}
+
+	// Malformed argon2id hash
+	if _, _, err := VerifyPassword("$argon2id$v=19$m=1024$salt$key", "Secret-123"); !errors.Is(err, ErrUnknownPasswordHash) {
+		t.Errorf("malformed hash: err %v", err)
+	}
+}
+
+func TestSetupHasherInvalid(t *testing.T) {
+	if err := SetupHasher(testHashConfig("md5")); err == nil {
+		t.Error("unknown algorithm is accepted")
+	}
+
+	cfg := testHashConfig(HashBcrypt)
+	cfg.BcryptCost = bcrypt.MaxCost + 1
+	if err := SetupHasher(cfg); err == nil {
+		t.Error("invalid bcrypt cost is accepted")
+	}
+
+	for name, weaken := range map[string]func(*config.PasswordHashConfig){
+		"time":             func(cfg *config.PasswordHashConfig) { cfg.ArgonTime = 0 },
+		"memory":           func(cfg *config.PasswordHashConfig) { cfg.ArgonMemory = 64 },
+		"threads":          func(cfg *config.PasswordHashConfig) { cfg.ArgonThreads = 0 },
+		"threads of uint8": func(cfg *config.PasswordHashConfig) { cfg.ArgonThreads = 256 },
+		"key length":       func(cfg *config.PasswordHashConfig) { cfg.ArgonKeyLength = 8 },
+	} {
+		cfg := testHashConfig(HashArgon2id)
+		weaken(&cfg)
+		if err := SetupHasher(cfg); err == nil {
+			t.Errorf("invalid argon2id %s is accepted", name)
+		}
+	}
+}
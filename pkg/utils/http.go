@@ -7,10 +7,14 @@ import (
 	"fmt"
 	"io/ioutil"
 	"log"
+	"math"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
+	"time"
 
+	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 )
@@ -62,6 +66,14 @@ func APIResponse(c *fiber.Ctx, message string, code int, status string, data int
 	})
 }
 
+// APIResponseTooManyRequests func to answer 429 with the Retry-After header in seconds.
+func APIResponseTooManyRequests(c *fiber.Ctx, message string, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+
+	return APIResponse(c, message, fiber.StatusTooManyRequests, fiber.ErrTooManyRequests.Error(), fiber.Map{"retry_after": seconds})
+}
+
 func FormatValidationError(err error) []string {
 	var errors []string
 
@@ -80,6 +92,14 @@ func APIResponseErrorByValidationError(c *fiber.Ctx, err error) error {
 	return response
 }
 
+// APIResponseErrorByPasswordPolicy func to answer the broken password policy rules like a validation error.
+func APIResponseErrorByPasswordPolicy(c *fiber.Ctx, err PasswordPolicyErrors, trans ut.Translator) error {
+	errorMessage := fiber.Map{"errors": err.Translate(trans)}
+	response := APIResponse(c, fmt.Sprintf(`%s failed.`, c.Path()), fiber.StatusUnprocessableEntity, fiber.ErrUnprocessableEntity.Message, errorMessage)
+
+	return response
+}
+
 func EnsureDir(dirName string, mode os.FileMode) error {
 	err := os.Mkdir(dirName, mode)
 	if err == nil || os.IsExist(err) {
@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fiber-starter/config"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	JWT_DEFAULT_KID    = "default"
+	jwtPrivateKeyExt   = ".pem"
+	jwtPublicKeyExt    = ".pub.pem"
+	jwtKeyUseSignature = "sig"
+)
+
+type signingKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey interface{}
+	PublicKey  interface{}
+}
+
+// JWK public key in JSON Web Key format, see RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+var (
+	jwtActiveKey *signingKey
+	jwtKeys      map[string]*signingKey
+)
+
+// loadSigningKeys load the HMAC app key, or every asymmetric key in the keys directory.
+// Files <kid>.pem hold private keys able to sign, files <kid>.pub.pem hold public keys
+// kept only to verify tokens signed by a rotated key.
+func loadSigningKeys(cfg config.JWTConfig) error {
+	jwtKeys = map[string]*signingKey{}
+
+	// Shared secret when no key directory configured
+	if len(cfg.KeysDir) <= 0 {
+		kid := cfg.ActiveKeyID
+		if len(kid) <= 0 {
+			kid = JWT_DEFAULT_KID
+		}
+		jwtActiveKey = &signingKey{ID: kid, Method: jwt.SigningMethodHS256, PrivateKey: []byte(cfg.Secret), PublicKey: []byte(cfg.Secret)}
+		jwtKeys[kid] = jwtActiveKey
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(cfg.KeysDir, "*"+jwtPrivateKeyExt))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		pem, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		var key *signingKey
+		name := filepath.Base(file)
+		if strings.HasSuffix(name, jwtPublicKeyExt) {
+			key, err = parsePublicKey(strings.TrimSuffix(name, jwtPublicKeyExt), pem)
+		} else {
+			key, err = parsePrivateKey(strings.TrimSuffix(name, jwtPrivateKeyExt), pem)
+		}
+		if err != nil {
+			return fmt.Errorf("jwt key %s: %v", name, err)
+		}
+
+		// Private key wins over a public key with the same kid
+		if current, ok := jwtKeys[key.ID]; ok && current.PrivateKey != nil {
+			continue
+		}
+		jwtKeys[key.ID] = key
+	}
+
+	active, ok := jwtKeys[cfg.ActiveKeyID]
+	if !ok || active.PrivateKey == nil {
+		return fmt.Errorf("jwt active key %s has no private key in %s", cfg.ActiveKeyID, cfg.KeysDir)
+	}
+	jwtActiveKey = active
+
+	return nil
+}
+
+func parsePrivateKey(kid string, pem []byte) (*signingKey, error) {
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, PrivateKey: rsaKey, PublicKey: &rsaKey.PublicKey}, nil
+	}
+
+	edKey, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "unsupported private key, expected RSA or Ed25519")
+	}
+	privateKey := edKey.(ed25519.PrivateKey)
+
+	return &signingKey{ID: kid, Method: jwt.SigningMethodEdDSA, PrivateKey: privateKey, PublicKey: privateKey.Public()}, nil
+}
+
+func parsePublicKey(kid string, pem []byte) (*signingKey, error) {
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(pem); err == nil {
+		return &signingKey{ID: kid, Method: jwt.SigningMethodRS256, PublicKey: rsaKey}, nil
+	}
+
+	edKey, err := jwt.ParseEdPublicKeyFromPEM(pem)
+	if err != nil {
+		return nil, fmt.Errorf("%s", "unsupported public key, expected RSA or Ed25519")
+	}
+
+	return &signingKey{ID: kid, Method: jwt.SigningMethodEdDSA, PublicKey: edKey}, nil
+}
+
+// signJWT sign claims with the active key and set its kid header.
+func signJWT(claims jwt.Claims) (string, error) {
+	rawToken := jwt.NewWithClaims(jwtActiveKey.Method, claims)
+	rawToken.Header["kid"] = jwtActiveKey.ID
+
+	return rawToken.SignedString(jwtActiveKey.PrivateKey)
+}
+
+// GetJWKS func to list public keys able to verify issued tokens.
+func GetJWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, key := range jwtKeys {
+		switch publicKey := key.PublicKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.ID,
+				Use: jwtKeyUseSignature,
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.ID,
+				Use: jwtKeyUseSignature,
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(publicKey),
+			})
+		}
+	}
+
+	return jwks
+}
+
+func jwtKeyFunc(token *jwt.Token) (interface{}, error) {
+	// Token without kid header only valid for the shared secret
+	kid, _ := token.Header["kid"].(string)
+	if len(kid) <= 0 {
+		kid = JWT_DEFAULT_KID
+	}
+
+	key, ok := jwtKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown token key %s", kid)
+	}
+
+	// Prevent algorithm confusion between key types
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+	}
+
+	return key.PublicKey, nil
+}
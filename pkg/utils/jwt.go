@@ -1,80 +1,383 @@
 package utils
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fiber-starter/config"
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt"
 )
 
 type TokenMetaData struct {
-	ID      int64
-	Code    string
-	Phone   string
-	Email   string
-	Role    string
-	Expires int64
+	ID       int64
+	JTI      string
+	Type     string
+	Code     string
+	Phone    string
+	Email    string
+	Role     string
+	ClientID string
+	Scope    string
+	Purpose  string
+	Session  string
+	IssuedAt int64
+	Expires  int64
 }
 
-func GenerateJWT(id int64, code, phone, email, role string) (string, int64, error) {
-	// Set expired time
-	expirationTime := time.Now().Add(2160 * time.Hour).Unix()
+const (
+	JWT_TYPE_ACCESS   = "access"
+	JWT_TYPE_MFA      = "mfa"
+	JWT_TYPE_OAUTH    = "oauth"
+	JWT_TYPE_VERIFY   = "verify"
+	JWT_TYPE_PASSWORD = "password"
+)
+
+const (
+	jwtDenylistPrefix  = "jwt:denylist:"
+	jwtWatermarkPrefix = "jwt:watermark:"
+	jwtVerifyPrefix    = "jwt:verify:"
+	jwtFailurePrefix   = "jwt:failure:"
+)
+
+var (
+	ErrMissingJWT          = errors.New("Missing or malformed JWT")
+	ErrVerificationJWTUsed = errors.New("token has been used or expired")
+)
+
+var (
+	jwtConfig config.JWTConfig
+	jwtStore  *redis.Client
+)
+
+// SetupJWT func to set signing keys, token lifetimes and the revocation store used by tokens.
+func SetupJWT(cfg config.JWTConfig, store *redis.Client) error {
+	jwtConfig = cfg
+	jwtStore = store
+
+	return loadSigningKeys(cfg)
+}
+
+// GenerateJWT func to create an access token bound to the login session of the user.
+func GenerateJWT(id int64, code, phone, email, role, session string) (string, int64, error) {
+	claims, expirationTime, err := newClaims(JWT_TYPE_ACCESS, id, code, phone, email, role, jwtConfig.AccessTTL)
+	if err != nil {
+		return "", 0, err
+	}
+	claims["sid"] = session
+
+	// Create a new JWT token with claims signed by the active key.
+	token, err := signJWT(claims)
+
+	return token, expirationTime, err
+}
+
+// GenerateScopedJWT func to create a token only accepted where its type is expected.
+func GenerateScopedJWT(tokenType string, id int64, code, phone, email, role string, ttl time.Duration) (string, int64, error) {
+	claims, expirationTime, err := newClaims(tokenType, id, code, phone, email, role, ttl)
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Create a new JWT token with claims signed by the active key.
+	token, err := signJWT(claims)
+
+	return token, expirationTime, err
+}
+
+// GenerateOAuthJWT func to create an access token issued to an oauth client, limited to the granted scopes.
+func GenerateOAuthJWT(id int64, code, role, clientID string, scopes []string, ttl time.Duration) (string, int64, error) {
+	claims, expirationTime, err := newClaims(JWT_TYPE_OAUTH, id, code, "", "", role, ttl)
+	if err != nil {
+		return "", 0, err
+	}
+	claims["client_id"] = clientID
+	claims["scope"] = strings.Join(scopes, " ")
+
+	// Create a new JWT token with claims signed by the active key.
+	token, err := signJWT(claims)
+
+	return token, expirationTime, err
+}
+
+// GenerateVerificationJWT func to create a single-use token sent to the user, only accepted for its purpose.
+func GenerateVerificationJWT(purpose string, id int64, code, phone, email string, ttl time.Duration) (string, int64, error) {
+	claims, expirationTime, err := newClaims(JWT_TYPE_VERIFY, id, code, phone, email, "", ttl)
+	if err != nil {
+		return "", 0, err
+	}
+	claims["purpose"] = purpose
+
+	// Store token id until used or expired
+	err = jwtStore.Set(context.Background(), jwtVerifyPrefix+fmt.Sprintf("%s", claims["jti"]), purpose, ttl).Err()
+	if err != nil {
+		return "", 0, err
+	}
+
+	// Create a new JWT token with claims signed by the active key.
+	token, err := signJWT(claims)
+
+	return token, expirationTime, err
+}
+
+// VerifyVerificationJWT func to check a verification token of the purpose without using it.
+func VerifyVerificationJWT(tokenString, purpose string) (*TokenMetaData, error) {
+	tokenMetaData, err := decodeVerificationJWT(tokenString, purpose)
+	if err != nil {
+		return nil, err
+	}
 
-	// Set secret key from .env file.
-	appKey := os.Getenv("APP_KEY")
+	stored, err := jwtStore.Exists(context.Background(), jwtVerifyPrefix+tokenMetaData.JTI).Result()
+	if err != nil {
+		return nil, err
+	}
+	if stored <= 0 {
+		return nil, ErrVerificationJWTUsed
+	}
+
+	return tokenMetaData, nil
+}
+
+// ConsumeVerificationJWT func to use a verification token of the purpose, a token is usable once.
+func ConsumeVerificationJWT(tokenString, purpose string) (*TokenMetaData, error) {
+	tokenMetaData, err := decodeVerificationJWT(tokenString, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	deleted, err := jwtStore.Del(context.Background(), jwtVerifyPrefix+tokenMetaData.JTI).Result()
+	if err != nil {
+		return nil, err
+	}
+	if deleted <= 0 {
+		return nil, ErrVerificationJWTUsed
+	}
+
+	return tokenMetaData, nil
+}
+
+func decodeVerificationJWT(tokenString, purpose string) (*TokenMetaData, error) {
+	tokenMetaData, err := DecodeScopedJWT(tokenString, JWT_TYPE_VERIFY)
+	if err != nil {
+		return nil, err
+	}
+	if tokenMetaData == nil {
+		return nil, ErrMissingJWT
+	}
+	if tokenMetaData.Purpose != purpose {
+		return nil, fmt.Errorf("%s", "token was issued for another purpose")
+	}
+
+	return tokenMetaData, nil
+}
+
+func newClaims(tokenType string, id int64, code, phone, email, role string, ttl time.Duration) (jwt.MapClaims, int64, error) {
+	// Set issued and expired time
+	now := time.Now()
+	expirationTime := now.Add(ttl).Unix()
+
+	// Set unique token id
+	jti, err := generateTokenID()
+	if err != nil {
+		return nil, 0, err
+	}
 
 	// Create a new claims.
 	claims := jwt.MapClaims{}
 
 	// Set public claims:
 	claims["id"] = id
+	claims["jti"] = jti
+	claims["typ"] = tokenType
 	claims["code"] = code
 	claims["phone"] = phone
 	claims["email"] = email
 	claims["role"] = role
+	claims["iat"] = now.Unix()
 	claims["exp"] = expirationTime
 
-	// Create a new JWT access token with claims.
-	rawToken := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err := rawToken.SignedString([]byte(appKey))
+	return claims, expirationTime, nil
+}
 
-	return token, expirationTime, err
+// GenerateRefreshToken func to create an opaque refresh token, returns the token, its hash and expired time.
+func GenerateRefreshToken() (string, string, time.Time, error) {
+	token, tokenHash, err := GenerateOpaqueToken()
+	if err != nil {
+		return "", "", time.Time{}, err
+	}
+	expiredTime := time.Now().In(time.UTC).Add(jwtConfig.RefreshTTL)
+
+	return token, tokenHash, expiredTime, nil
+}
+
+// GenerateOpaqueToken func to create a random url safe token, returns the token and its hash to store.
+func GenerateOpaqueToken() (string, string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	return token, HashToken(token), nil
+}
+
+// HashToken func to hash an opaque token before storing it.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
 
 // ExtractTokenMetadata func to extract metadata from JWT.
 func ExtractTokenMetadata(c *fiber.Ctx) (*TokenMetaData, error) {
-	var err error
-	var tokenMetaData *TokenMetaData
-
 	token, err := verifyToken(c)
 	if err != nil {
 		return nil, err
 	}
 
+	return checkTokenMetadata(token, JWT_TYPE_ACCESS)
+}
+
+// DecodeScopedJWT func to verify a token string issued for the given type.
+func DecodeScopedJWT(tokenString, tokenType string) (*TokenMetaData, error) {
+	token, err := jwt.Parse(tokenString, jwtKeyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	return checkTokenMetadata(token, tokenType)
+}
+
+func checkTokenMetadata(token *jwt.Token, tokenType string) (*TokenMetaData, error) {
+	var tokenMetaData *TokenMetaData
+
 	// Setting and checking token and credentials.
 	claims, ok := token.Claims.(jwt.MapClaims)
 	if ok && token.Valid {
+		// Tokens without id or expiry are not issued here
+		id, ok := claims["id"].(float64)
+		if !ok {
+			return nil, ErrMissingJWT
+		}
+		exp, ok := claims["exp"].(float64)
+		if !ok {
+			return nil, ErrMissingJWT
+		}
+
 		tokenMetaData = &TokenMetaData{
-			ID:      int64(claims["id"].(float64)),
+			ID:      int64(id),
+			JTI:     fmt.Sprintf("%s", claims["jti"]),
+			Type:    fmt.Sprintf("%s", claims["typ"]),
 			Code:    fmt.Sprintf("%s", claims["code"]),
 			Phone:   fmt.Sprintf("%s", claims["phone"]),
 			Email:   fmt.Sprintf("%s", claims["email"]),
 			Role:    fmt.Sprintf("%s", claims["role"]),
-			Expires: int64(claims["exp"].(float64)),
+			Expires: int64(exp),
 		}
+		if iat, ok := claims["iat"].(float64); ok {
+			tokenMetaData.IssuedAt = int64(iat)
+		}
+		tokenMetaData.ClientID, _ = claims["client_id"].(string)
+		tokenMetaData.Scope, _ = claims["scope"].(string)
+		tokenMetaData.Purpose, _ = claims["purpose"].(string)
+		tokenMetaData.Session, _ = claims["sid"].(string)
+
+		// Check token issued for another usage
+		if tokenMetaData.Type != tokenType {
+			return nil, fmt.Errorf("%s", "invalid token type")
+		}
+
+		// Check revoked token
+		if err := checkTokenRevoked(tokenMetaData); err != nil {
+			return nil, err
+		}
+	}
+
+	return tokenMetaData, nil
+}
+
+// RevokeToken func to deny a token until its expired time.
+func RevokeToken(tokenMetaData *TokenMetaData) error {
+	ttl := time.Until(time.Unix(tokenMetaData.Expires, 0))
+	if ttl <= 0 {
+		return nil
+	}
+
+	return jwtStore.Set(context.Background(), jwtDenylistPrefix+tokenMetaData.JTI, 1, ttl).Err()
+}
+
+// FailToken func to count a failed use of the token, the count expires with the token.
+func FailToken(tokenMetaData *TokenMetaData) (int64, error) {
+	ctx := context.Background()
+	key := jwtFailurePrefix + tokenMetaData.JTI
+
+	failures, err := jwtStore.Incr(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if failures == 1 {
+		if err := jwtStore.ExpireAt(ctx, key, time.Unix(tokenMetaData.Expires, 0)).Err(); err != nil {
+			return failures, err
+		}
+	}
+
+	return failures, nil
+}
+
+// RevokeUserTokens func to deny every token of a user issued until now, the watermark is kept
+// for the longest lifetime of the access token and the scoped tokens given by scopedTTL.
+func RevokeUserTokens(code string, scopedTTL time.Duration) error {
+	ttl := jwtConfig.AccessTTL
+	if scopedTTL > ttl {
+		ttl = scopedTTL
 	}
 
-	return tokenMetaData, err
+	return jwtStore.Set(context.Background(), jwtWatermarkPrefix+code, time.Now().Unix(), ttl).Err()
+}
+
+func checkTokenRevoked(tokenMetaData *TokenMetaData) error {
+	ctx := context.Background()
+
+	// Check denylist by token id
+	denied, err := jwtStore.Exists(ctx, jwtDenylistPrefix+tokenMetaData.JTI).Result()
+	if err != nil {
+		return err
+	}
+	if denied > 0 {
+		return fmt.Errorf("%s", "token has been revoked")
+	}
+
+	// Check user watermark, tokens issued until its second are revoked
+	watermark, err := jwtStore.Get(ctx, jwtWatermarkPrefix+tokenMetaData.Code).Int64()
+	if err != nil && err != redis.Nil {
+		return err
+	}
+	if err == nil && tokenMetaData.IssuedAt <= watermark {
+		return fmt.Errorf("%s", "token has been revoked")
+	}
+
+	return nil
+}
+
+func generateTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
 }
 
 func DecodeTokenJWT(token string) (map[string]interface{}, error) {
 	claims := jwt.MapClaims{}
-	_, err := jwt.ParseWithClaims(token, claims, func(tokenTemp *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("APP_KEY")), nil
-	})
+	_, err := jwt.ParseWithClaims(token, claims, jwtKeyFunc)
 	if err != nil {
 		return claims, err
 	}
@@ -96,6 +399,9 @@ func extractToken(c *fiber.Ctx) string {
 
 func verifyToken(c *fiber.Ctx) (*jwt.Token, error) {
 	tokenString := extractToken(c)
+	if len(tokenString) <= 0 {
+		return nil, ErrMissingJWT
+	}
 
 	token, err := jwt.Parse(tokenString, jwtKeyFunc)
 	if err != nil {
@@ -104,7 +410,3 @@ func verifyToken(c *fiber.Ctx) (*jwt.Token, error) {
 
 	return token, nil
 }
-
-func jwtKeyFunc(token *jwt.Token) (interface{}, error) {
-	return []byte(os.Getenv("APP_KEY")), nil
-}
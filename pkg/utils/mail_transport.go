@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	MailTransportSMTP   = "smtp"
+	MailTransportFile   = "file"
+	MailTransportMemory = "memory"
+
+	// mailMemoryLimit latest messages kept by the memory transport
+	mailMemoryLimit = 100
+)
+
+// MailTransport delivers the message built for every recipient.
+type MailTransport interface {
+	Send(from string, to []string, build func(to string) ([]byte, error)) MailResults
+}
+
+// MailboxMessage message captured by the file or memory transport
+type MailboxMessage struct {
+	ID      string
+	From    string
+	To      string
+	Subject string
+	Date    time.Time
+	Text    string
+	Raw     []byte
+}
+
+var (
+	mailTransport MailTransport
+	mailMemory    = &memoryMailTransport{}
+)
+
+// CapturedMails func to get the messages of the memory transport, newest first. The memory transport
+// only holds the mails sent by its own process, it is meant for tests and not for /dev/mailbox.
+func CapturedMails() []MailboxMessage {
+	return mailMemory.messages()
+}
+
+// ResetCapturedMails func to forget the messages of the memory transport.
+func ResetCapturedMails() {
+	mailMemory.reset()
+}
+
+// MailboxMessages func to list the messages of the file transport in dir, newest first. The queue consumer
+// writes them, so the api process reads them from the maildir shared with it.
+func MailboxMessages(dir string, limit int) ([]MailboxMessage, error) {
+	list, err := (&fileMailTransport{dir: dir}).messages()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Date.After(list[j].Date)
+	})
+	if limit > 0 && len(list) > limit {
+		list = list[:limit]
+	}
+
+	return list, nil
+}
+
+// smtpMailTransport sends through the pooled connections of the smtp server
+type smtpMailTransport struct{}
+
+func (t *smtpMailTransport) Send(from string, to []string, build func(to string) ([]byte, error)) MailResults {
+	return SendSMTP(from, to, build)
+}
+
+// fileMailTransport writes every message as an .eml file in a maildir, for local development
+type fileMailTransport struct {
+	dir string
+}
+
+func (t *fileMailTransport) Send(from string, to []string, build func(to string) ([]byte, error)) MailResults {
+	results := MailResults{}
+	for _, rcpt := range to {
+		msg, err := build(rcpt)
+		if err == nil {
+			err = t.write(msg)
+		}
+		results = append(results, MailResult{To: rcpt, Err: err})
+	}
+
+	return results
+}
+
+// write the message in tmp then move it to new, readers never see a partial file
+func (t *fileMailTransport) write(msg []byte) error {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(t.dir, sub), 0755); err != nil {
+			return err
+		}
+	}
+
+	b := make([]byte, 6)
+	rand.Read(b)
+	name := fmt.Sprintf("%d.%s.eml", time.Now().UnixNano(), hex.EncodeToString(b))
+	tmp := filepath.Join(t.dir, "tmp", name)
+	if err := ioutil.WriteFile(tmp, msg, 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(t.dir, "new", name))
+}
+
+// messages read the new and the seen messages of the maildir
+func (t *fileMailTransport) messages() ([]MailboxMessage, error) {
+	var list []MailboxMessage
+	for _, sub := range []string{"new", "cur"} {
+		files, err := ioutil.ReadDir(filepath.Join(t.dir, sub))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			if file.IsDir() || !strings.HasSuffix(file.Name(), ".eml") {
+				continue
+			}
+			raw, err := ioutil.ReadFile(filepath.Join(t.dir, sub, file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, parseMailboxMessage(file.Name(), raw))
+		}
+	}
+
+	return list, nil
+}
+
+// memoryMailTransport keeps the latest messages in the process, for tests only since the mails are sent by the queue consumer
+type memoryMailTransport struct {
+	mu   sync.Mutex
+	seq  int
+	list []MailboxMessage
+}
+
+func (t *memoryMailTransport) Send(from string, to []string, build func(to string) ([]byte, error)) MailResults {
+	results := MailResults{}
+	for _, rcpt := range to {
+		msg, err := build(rcpt)
+		if err == nil {
+			t.mu.Lock()
+			t.seq++
+			t.list = append(t.list, parseMailboxMessage(fmt.Sprintf("memory-%d", t.seq), msg))
+			if len(t.list) > mailMemoryLimit {
+				t.list = t.list[len(t.list)-mailMemoryLimit:]
+			}
+			t.mu.Unlock()
+		}
+		results = append(results, MailResult{To: rcpt, Err: err})
+	}
+
+	return results
+}
+
+func (t *memoryMailTransport) messages() []MailboxMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	list := make([]MailboxMessage, 0, len(t.list))
+	for i := len(t.list) - 1; i >= 0; i-- {
+		list = append(list, t.list[i])
+	}
+
+	return list
+}
+
+func (t *memoryMailTransport) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.list = nil
+}
+
+// parseMailboxMessage read the headers and the text alternative of the message, as far as it is readable
+func parseMailboxMessage(id string, raw []byte) MailboxMessage {
+	message := MailboxMessage{ID: id, Raw: raw}
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return message
+	}
+
+	decoder := new(mime.WordDecoder)
+	header := func(key string) string {
+		value, err := decoder.DecodeHeader(msg.Header.Get(key))
+		if err != nil {
+			return msg.Header.Get(key)
+		}
+		return value
+	}
+	message.From = header("From")
+	message.To = header("To")
+	message.Subject = header("Subject")
+	message.Date, _ = msg.Header.Date()
+	message.Text = mailText(msg.Body, msg.Header.Get("Content-Type"))
+
+	return message
+}
+
+// mailText find the first text/plain part, multipart decodes its quoted-printable
+func mailText(r io.Reader, contentType string) string {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	if mediaType == "text/plain" {
+		b, _ := ioutil.ReadAll(r)
+		return string(b)
+	}
+	if !strings.HasPrefix(mediaType, "multipart/") {
+		return ""
+	}
+
+	mr := multipart.NewReader(r, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err != nil {
+			return ""
+		}
+		if text := mailText(part, part.Header.Get("Content-Type")); len(text) > 0 {
+			return text
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"fiber-starter/config"
+	"strings"
+	"testing"
+)
+
+func TestMemoryTransportCapturesMails(t *testing.T) {
+	if err := SetupMail(config.MailConfig{Transport: MailTransportMemory, FromAddress: "noreply@sample.com", FromName: "Sample"}); err != nil {
+		t.Fatal(err)
+	}
+	defer CloseMail()
+	ResetCapturedMails()
+	defer ResetCapturedMails()
+
+	r := &MailRequest{
+		to:      []string{"first@sample.com", "second@sample.com"},
+		subject: "[sample] Login",
+		body:    "<p>Your code is <b>123456</b></p>",
+	}
+	if err := r.sendMail().Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	mails := CapturedMails()
+	if len(mails) != 2 {
+		t.Fatalf("captured %d mails, want 2", len(mails))
+	}
+
+	// Newest first
+	if !strings.Contains(mails[0].To, "second@sample.com") || !strings.Contains(mails[1].To, "first@sample.com") {
+		t.Errorf("captured to %q and %q", mails[0].To, mails[1].To)
+	}
+	for _, mail := range mails {
+		if mail.Subject != "[sample] Login" {
+			t.Errorf("subject %q", mail.Subject)
+		}
+		if !strings.Contains(mail.From, "noreply@sample.com") {
+			t.Errorf("from %q", mail.From)
+		}
+		if !strings.Contains(mail.Text, "Your code is 123456") {
+			t.Errorf("text %q", mail.Text)
+		}
+	}
+
+	ResetCapturedMails()
+	if len(CapturedMails()) != 0 {
+		t.Error("captured mails are kept after reset")
+	}
+}
+
+func TestMailboxListsOnlyFileMails(t *testing.T) {
+	dir := t.TempDir()
+	if err := SetupMail(config.MailConfig{Transport: MailTransportFile, FileDir: dir, FromAddress: "noreply@sample.com"}); err != nil {
+		t.Fatal(err)
+	}
+	defer CloseMail()
+
+	r := &MailRequest{to: []string{"file@sample.com"}, subject: "File", body: "<p>file</p>"}
+	if err := r.sendMail().Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A mail of the memory transport is not in the mailbox
+	mailMemory.Send("noreply@sample.com", []string{"memory@sample.com"}, func(to string) ([]byte, error) {
+		return MailMessage{From: "noreply@sample.com", To: to, Subject: "Memory", HTML: "<p>memory</p>"}.Bytes()
+	})
+	defer ResetCapturedMails()
+
+	list, err := MailboxMessages(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || !strings.Contains(list[0].To, "file@sample.com") {
+		t.Fatalf("mailbox has %d mails, want the file mail only", len(list))
+	}
+}
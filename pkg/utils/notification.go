@@ -0,0 +1,61 @@
+package utils
+
+import (
+	"bytes"
+	"context"
+	"fiber-starter/config"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// NotificationData delivery of a notification to one channel queued for the consumer
+type NotificationData struct {
+	DeliveryID int64
+	Channel    string
+	Recipient  string
+	Type       string
+	Title      string
+	Message    string
+	Data       interface{}
+	// Attachments of the email channel
+	Attachments []MailAttachment
+}
+
+func PushQueueNotification(config *config.RabbitMQ, qName string, data NotificationData) error {
+	queue := QueueRabbitMQ{RabbitMQ: config, Data: data}
+	err := PushQueueToRabbitMQ(queue, qName)
+
+	return err
+}
+
+// SendWebhook func to post the payload signed like the api requests, see SignatureCanonical.
+func SendWebhook(ctx context.Context, endpoint, secret string, payload []byte) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-TIMESTAMPT", timestamp)
+	req.Header.Set("X-SIGNATURE", SignRequest(secret, SignatureCanonical(http.MethodPost, u.RequestURI(), timestamp, payload)))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf(`webhook answered %d`, resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fiber-starter/config"
+	"fiber-starter/pkg/common"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	NOTIFY_VIA_SMS      = "sms"
+	NOTIFY_VIA_WHATSAPP = "whatsapp"
+
+	NotifierFake   = "fake"
+	NotifierTwilio = "twilio"
+)
+
+// PhoneData message queued to CMDQueueSendPhone before notifications
+type PhoneData struct {
+	Receiver string
+	Usage    string
+	Data     interface{}
+}
+
+// Notifier delivers text messages to a phone through a gateway.
+type Notifier interface {
+	Send(ctx context.Context, channel, to, message string) error
+}
+
+var (
+	notifiers      map[string]Notifier
+	notifierConfig config.NotifierConfig
+)
+
+// SetupNotifier func to set the providers and check every route uses a configured one.
+func SetupNotifier(cfg config.NotifierConfig) error {
+	notifierConfig = cfg
+	notifiers = map[string]Notifier{
+		NotifierFake: &fakeNotifier{file: cfg.FakeFile},
+	}
+	if len(cfg.Twilio.AccountSID) > 0 {
+		notifiers[NotifierTwilio] = &twilioNotifier{
+			cfg:    cfg.Twilio,
+			client: &http.Client{Timeout: cfg.Timeout},
+		}
+	}
+
+	routes := []config.NotifierRoute{cfg.Default}
+	for _, route := range cfg.Routes {
+		routes = append(routes, route)
+	}
+	for _, route := range routes {
+		if _, ok := notifiers[route.Provider]; !ok {
+			return fmt.Errorf(`notifier provider %s is not configured`, route.Provider)
+		}
+		if route.Channel != NOTIFY_VIA_SMS && route.Channel != NOTIFY_VIA_WHATSAPP {
+			return fmt.Errorf(`notifier channel %s is not supported`, route.Channel)
+		}
+	}
+
+	return nil
+}
+
+// RoutePhone func to find the route of the phone by its country, returns the phone in E.164.
+func RoutePhone(phone string) (config.NotifierRoute, string, error) {
+	country, number, valid := common.IsPhone(phone)
+	if !valid {
+		return config.NotifierRoute{}, "", fmt.Errorf(`phone %s is invalid`, phone)
+	}
+
+	route, ok := notifierConfig.Routes[country.Alpha2]
+	if !ok {
+		route = notifierConfig.Default
+	}
+
+	return route, "+" + number, nil
+}
+
+// SendPhoneText func to send the message through the route of the phone.
+func SendPhoneText(ctx context.Context, phone, message string) error {
+	route, to, err := RoutePhone(phone)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, notifierConfig.Timeout)
+	defer cancel()
+
+	return notifiers[route.Provider].Send(ctx, route.Channel, to, message)
+}
+
+// fakeNotifier appends messages to a file instead of a gateway, for local testing
+type fakeNotifier struct {
+	file string
+	mu   sync.Mutex
+}
+
+func (n *fakeNotifier) Send(ctx context.Context, channel, to, message string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(n.file), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(n.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	line, err := json.Marshal(map[string]string{
+		"time":    time.Now().In(time.UTC).Format(time.RFC3339),
+		"channel": channel,
+		"to":      to,
+		"message": message,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(line, '\n'))
+
+	return err
+}
+
+// twilioNotifier sends sms and whatsapp messages through the twilio messages api
+type twilioNotifier struct {
+	cfg    config.NotifierTwilioConfig
+	client *http.Client
+}
+
+func (n *twilioNotifier) Send(ctx context.Context, channel, to, message string) error {
+	from := n.cfg.From
+	if channel == NOTIFY_VIA_WHATSAPP {
+		from, to = "whatsapp:"+n.cfg.WhatsAppFrom, "whatsapp:"+to
+	}
+
+	form := url.Values{}
+	form.Set("From", from)
+	form.Set("To", to)
+	form.Set("Body", message)
+
+	endpoint := fmt.Sprintf("https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json", n.cfg.AccountSID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(n.cfg.AccountSID, n.cfg.AuthToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var body struct {
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&body)
+		return fmt.Errorf(`twilio answered %d: %s`, resp.StatusCode, body.Message)
+	}
+
+	return nil
+}
@@ -0,0 +1,363 @@
+package utils
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fiber-starter/config"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const oidcPKCEAlphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~"
+
+var ErrUnknownOIDCProvider = errors.New("unknown identity provider")
+
+// OIDCClaims identity of the end-user verified from the provider id token.
+type OIDCClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// OIDCProvider sign in through an external identity provider with the
+// authorization code flow and PKCE.
+type OIDCProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (OIDCClaims, error)
+}
+
+var (
+	oidcProviders   = map[string]OIDCProvider{}
+	oidcProvidersMu sync.RWMutex
+)
+
+// SetupOIDC func to register a discovery based provider for every configured provider.
+func SetupOIDC(cfg config.OIDCConfig) {
+	for name, providerCfg := range cfg.Providers {
+		RegisterOIDCProvider(NewOIDCProvider(name, providerCfg))
+	}
+}
+
+// RegisterOIDCProvider func to plug a provider, replacing the one with the same name.
+func RegisterOIDCProvider(provider OIDCProvider) {
+	oidcProvidersMu.Lock()
+	defer oidcProvidersMu.Unlock()
+
+	oidcProviders[provider.Name()] = provider
+}
+
+// GetOIDCProvider func to find a registered provider by name.
+func GetOIDCProvider(name string) (OIDCProvider, error) {
+	oidcProvidersMu.RLock()
+	defer oidcProvidersMu.RUnlock()
+
+	provider, ok := oidcProviders[name]
+	if !ok {
+		return nil, ErrUnknownOIDCProvider
+	}
+
+	return provider, nil
+}
+
+// GeneratePKCE func to create a code verifier and its S256 code challenge, see RFC 7636.
+func GeneratePKCE() (string, string, error) {
+	verifier, err := RandomString(64, oidcPKCEAlphabet)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type oidcJWK struct {
+	JWK
+	Y string `json:"y,omitempty"`
+}
+
+// oidcProvider generic provider configured from the issuer discovery document.
+type oidcProvider struct {
+	name       string
+	cfg        config.OIDCProviderConfig
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	discovery *oidcDiscovery
+	keys      map[string]interface{}
+}
+
+func NewOIDCProvider(name string, cfg config.OIDCProviderConfig) *oidcProvider {
+	return &oidcProvider{
+		name:       name,
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *oidcProvider) Name() string {
+	return p.name
+}
+
+func (p *oidcProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	query := url.Values{}
+	query.Set("response_type", "code")
+	query.Set("client_id", p.cfg.ClientID)
+	query.Set("redirect_uri", p.cfg.RedirectURL)
+	query.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+
+	return discovery.AuthorizationEndpoint + separator + query.Encode(), nil
+}
+
+func (p *oidcProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (OIDCClaims, error) {
+	var claims OIDCClaims
+
+	discovery, err := p.getDiscovery(ctx)
+	if err != nil {
+		return claims, err
+	}
+
+	// Exchange authorization code
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("client_id", p.cfg.ClientID)
+	form.Set("code_verifier", codeVerifier)
+	if len(p.cfg.ClientSecret) > 0 {
+		form.Set("client_secret", p.cfg.ClientSecret)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return claims, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+
+	var tokenResponse struct {
+		IDToken          string `json:"id_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.doJSON(request, &tokenResponse); err != nil && len(tokenResponse.Error) <= 0 {
+		return claims, err
+	}
+	if len(tokenResponse.Error) > 0 {
+		return claims, fmt.Errorf("%s: %s %s", p.name, tokenResponse.Error, tokenResponse.ErrorDescription)
+	}
+	if len(tokenResponse.IDToken) <= 0 {
+		return claims, fmt.Errorf("%s: %s", p.name, "missing id token")
+	}
+
+	return p.verifyIDToken(ctx, discovery, tokenResponse.IDToken, nonce)
+}
+
+// verifyIDToken check signature, issuer, audience, expiry and nonce of the id token.
+func (p *oidcProvider) verifyIDToken(ctx context.Context, discovery *oidcDiscovery, idToken, nonce string) (OIDCClaims, error) {
+	var claims OIDCClaims
+
+	mapClaims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(idToken, mapClaims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+		}
+
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, discovery, kid)
+	})
+	if err != nil {
+		return claims, err
+	}
+
+	if !mapClaims.VerifyIssuer(discovery.Issuer, true) {
+		return claims, fmt.Errorf("%s: %s", p.name, "invalid id token issuer")
+	}
+	if !mapClaims.VerifyAudience(p.cfg.ClientID, true) {
+		return claims, fmt.Errorf("%s: %s", p.name, "invalid id token audience")
+	}
+	if tokenNonce, _ := mapClaims["nonce"].(string); tokenNonce != nonce {
+		return claims, fmt.Errorf("%s: %s", p.name, "invalid id token nonce")
+	}
+
+	claims.Subject, _ = mapClaims["sub"].(string)
+	claims.Email, _ = mapClaims["email"].(string)
+	claims.Name, _ = mapClaims["name"].(string)
+	claims.Email = strings.ToLower(strings.TrimSpace(claims.Email))
+
+	// Some providers send email_verified as a string
+	switch verified := mapClaims["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = verified
+	case string:
+		claims.EmailVerified = verified == "true"
+	}
+
+	if len(claims.Subject) <= 0 {
+		return claims, fmt.Errorf("%s: %s", p.name, "missing id token subject")
+	}
+
+	return claims, nil
+}
+
+func (p *oidcProvider) getDiscovery(ctx context.Context) (*oidcDiscovery, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil {
+		return p.discovery, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var discovery oidcDiscovery
+	if err := p.doJSON(request, &discovery); err != nil {
+		return nil, err
+	}
+	if strings.TrimRight(discovery.Issuer, "/") != strings.TrimRight(p.cfg.Issuer, "/") {
+		return nil, fmt.Errorf("%s: %s", p.name, "discovery issuer mismatch")
+	}
+	p.discovery = &discovery
+
+	return p.discovery, nil
+}
+
+// getKey find the verification key by kid, keys are fetched again once on an unknown kid.
+func (p *oidcProvider) getKey(ctx context.Context, discovery *oidcDiscovery, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key, ok := p.keys[kid]; ok {
+		return key, nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, discovery.JWKSURI, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var jwks struct {
+		Keys []oidcJWK `json:"keys"`
+	}
+	if err := p.doJSON(request, &jwks); err != nil {
+		return nil, err
+	}
+
+	p.keys = map[string]interface{}{}
+	for _, jwk := range jwks.Keys {
+		if key, err := parseOIDCJWK(jwk); err == nil {
+			p.keys[jwk.Kid] = key
+		}
+	}
+
+	key, ok := p.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown token key %s", kid)
+	}
+
+	return key, nil
+}
+
+func (p *oidcProvider) doJSON(request *http.Request, result interface{}) error {
+	response, err := p.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("%s: invalid response %d", p.name, response.StatusCode)
+	}
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: unexpected status %d", p.name, response.StatusCode)
+	}
+
+	return nil
+}
+
+func parseOIDCJWK(jwk oidcJWK) (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decode(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s", jwk.Crv)
+		}
+		x, err := decode(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %s", jwk.Kty)
+}
@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fiber-starter/config"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// testIssuer identity provider serving the discovery document and the keys it is given
+type testIssuer struct {
+	server *httptest.Server
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	issuer := &testIssuer{keys: map[string]*rsa.PrivateKey{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcDiscovery{
+			Issuer:                issuer.server.URL,
+			AuthorizationEndpoint: issuer.server.URL + "/authorize",
+			TokenEndpoint:         issuer.server.URL + "/token",
+			JWKSURI:               issuer.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		issuer.mu.Lock()
+		defer issuer.mu.Unlock()
+
+		var jwks struct {
+			Keys []oidcJWK `json:"keys"`
+		}
+		for kid, key := range issuer.keys {
+			jwks.Keys = append(jwks.Keys, oidcJWK{JWK: JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}})
+		}
+		json.NewEncoder(w).Encode(jwks)
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+// rotate replace the published keys by a new key of kid
+func (i *testIssuer) rotate(t *testing.T, kid string) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.keys = map[string]*rsa.PrivateKey{kid: key}
+}
+
+func (i *testIssuer) sign(t *testing.T, kid string, claims jwt.MapClaims) string {
+	i.mu.Lock()
+	key := i.keys[kid]
+	i.mu.Unlock()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+func (i *testIssuer) claims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            i.server.URL,
+		"aud":            "client-id",
+		"sub":            "subject-1",
+		"email":          " User@Sample.com ",
+		"email_verified": "true",
+		"nonce":          nonce,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+}
+
+func newTestProvider(t *testing.T, issuer *testIssuer) (*oidcProvider, *oidcDiscovery) {
+	provider := NewOIDCProvider("test", config.OIDCProviderConfig{Issuer: issuer.server.URL, ClientID: "client-id"})
+	discovery, err := provider.getDiscovery(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return provider, discovery
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.rotate(t, "key-1")
+	provider, discovery := newTestProvider(t, issuer)
+
+	claims, err := provider.verifyIDToken(context.Background(), discovery, issuer.sign(t, "key-1", issuer.claims("nonce-1")), "nonce-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Subject != "subject-1" || claims.Email != "user@sample.com" || !claims.EmailVerified {
+		t.Errorf("claims %+v", claims)
+	}
+}
+
+func TestVerifyIDTokenRejectsClaims(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.rotate(t, "key-1")
+	provider, discovery := newTestProvider(t, issuer)
+
+	tests := []struct {
+		name  string
+		claim string
+		value interface{}
+	}{
+		{"nonce", "nonce", "other-nonce"},
+		{"audience", "aud", "other-client"},
+		{"issuer", "iss", "https://other.example.com"},
+		{"expired", "exp", time.Now().Add(-time.Minute).Unix()},
+		{"subject", "sub", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := issuer.claims("nonce-1")
+			claims[tt.claim] = tt.value
+
+			_, err := provider.verifyIDToken(context.Background(), discovery, issuer.sign(t, "key-1", claims), "nonce-1")
+			if err == nil {
+				t.Errorf("id token with invalid %s is accepted", tt.name)
+			}
+		})
+	}
+}
+
+func TestVerifyIDTokenRejectsHMAC(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.rotate(t, "key-1")
+	provider, discovery := newTestProvider(t, issuer)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, issuer.claims("nonce-1"))
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString([]byte("client-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := provider.verifyIDToken(context.Background(), discovery, signed, "nonce-1"); err == nil {
+		t.Error("id token signed with HMAC is accepted")
+	}
+}
+
+func TestVerifyIDTokenKeyRotation(t *testing.T) {
+	issuer := newTestIssuer(t)
+	issuer.rotate(t, "key-1")
+	provider, discovery := newTestProvider(t, issuer)
+
+	if _, err := provider.verifyIDToken(context.Background(), discovery, issuer.sign(t, "key-1", issuer.claims("nonce-1")), "nonce-1"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Unknown kid fetches the keys again
+	issuer.rotate(t, "key-2")
+	if _, err := provider.verifyIDToken(context.Background(), discovery, issuer.sign(t, "key-2", issuer.claims("nonce-2")), "nonce-2"); err != nil {
+		t.Fatalf("id token of the rotated key: %v", err)
+	}
+
+	// Token signed by a key no longer published
+	other := newTestIssuer(t)
+	other.rotate(t, "key-3")
+	claims := other.claims("nonce-3")
+	claims["iss"] = issuer.server.URL
+	if _, err := provider.verifyIDToken(context.Background(), discovery, other.sign(t, "key-3", claims), "nonce-3"); err == nil {
+		t.Error("id token of an unknown key is accepted")
+	}
+}
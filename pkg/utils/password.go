@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fiber-starter/config"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+
+	ut "github.com/go-playground/universal-translator"
+)
+
+const passwordField = "password"
+
+// PasswordPolicyError rule of the password policy the password breaks.
+type PasswordPolicyError struct {
+	Key   string
+	Param string
+}
+
+// PasswordPolicyErrors every rule broken by a password.
+type PasswordPolicyErrors []PasswordPolicyError
+
+func (e PasswordPolicyErrors) Error() string {
+	return strings.Join(e.Translate(nil), ", ")
+}
+
+// Translate func to translate every broken rule, english is used without translator.
+func (e PasswordPolicyErrors) Translate(trans ut.Translator) []string {
+	var messages []string
+	for _, policyErr := range e {
+		if trans != nil {
+			if message, err := trans.T(policyErr.Key, passwordField, policyErr.Param); err == nil {
+				messages = append(messages, message)
+				continue
+			}
+		}
+
+		message := config.PasswordPolicyMessages[config.ENG][policyErr.Key]
+		message = strings.Replace(message, "{0}", passwordField, 1)
+		message = strings.Replace(message, "{1}", policyErr.Param, 1)
+		messages = append(messages, message)
+	}
+
+	return messages
+}
+
+var (
+	passwordPolicy config.PasswordPolicyConfig
+	// breachedRanges suffixes of breached password SHA-1 hashes by their 5 character prefix
+	breachedRanges map[string]map[string]struct{}
+)
+
+// SetupPasswordPolicy func to set the password policy and load the breached password ranges.
+func SetupPasswordPolicy(cfg config.PasswordPolicyConfig) error {
+	passwordPolicy = cfg
+	breachedRanges = map[string]map[string]struct{}{}
+	if len(cfg.BreachedFile) <= 0 {
+		return nil
+	}
+
+	file, err := os.Open(cfg.BreachedFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	// Lines are PREFIX:SUFFIX, optionally followed by :COUNT as answered by range apis
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		parts := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+		if len(parts) < 2 || len(parts[0]) != 5 {
+			continue
+		}
+		prefix, suffix := strings.ToUpper(parts[0]), strings.ToUpper(parts[1])
+		if _, ok := breachedRanges[prefix]; !ok {
+			breachedRanges[prefix] = map[string]struct{}{}
+		}
+		breachedRanges[prefix][suffix] = struct{}{}
+	}
+
+	return scanner.Err()
+}
+
+// CheckPasswordPolicy func to check a password against the policy, personal holds the name and
+// email of the user the password must not contain.
+func CheckPasswordPolicy(password string, personal ...string) error {
+	var errs PasswordPolicyErrors
+
+	// Length
+	length := len([]rune(password))
+	if length < passwordPolicy.MinLength {
+		errs = append(errs, PasswordPolicyError{config.PASSWORD_MIN_LENGTH, strconv.Itoa(passwordPolicy.MinLength)})
+	}
+	if passwordPolicy.MaxLength > 0 && length > passwordPolicy.MaxLength {
+		errs = append(errs, PasswordPolicyError{config.PASSWORD_MAX_LENGTH, strconv.Itoa(passwordPolicy.MaxLength)})
+	}
+
+	// Character classes
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if passwordPolicy.RequireUpper && !hasUpper {
+		errs = append(errs, PasswordPolicyError{Key: config.PASSWORD_UPPER})
+	}
+	if passwordPolicy.RequireLower && !hasLower {
+		errs = append(errs, PasswordPolicyError{Key: config.PASSWORD_LOWER})
+	}
+	if passwordPolicy.RequireDigit && !hasDigit {
+		errs = append(errs, PasswordPolicyError{Key: config.PASSWORD_DIGIT})
+	}
+	if passwordPolicy.RequireSymbol && !hasSymbol {
+		errs = append(errs, PasswordPolicyError{Key: config.PASSWORD_SYMBOL})
+	}
+
+	// Similarity to name or email
+	if passwordPolicy.RejectSimilar && isSimilarPassword(password, personal) {
+		errs = append(errs, PasswordPolicyError{Key: config.PASSWORD_SIMILAR})
+	}
+
+	// Breached password
+	if IsBreachedPassword(password) {
+		errs = append(errs, PasswordPolicyError{Key: config.PASSWORD_BREACHED})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// IsBreachedPassword func to find the password in the breached ranges by its SHA-1 hash prefix.
+func IsBreachedPassword(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, ok := breachedRanges[hash[:5]]
+	if !ok {
+		return false
+	}
+	_, breached := suffixes[hash[5:]]
+
+	return breached
+}
+
+// PasswordHistorySize func to get the number of previous passwords which can not be used again.
+func PasswordHistorySize() int {
+	return passwordPolicy.HistorySize
+}
+
+// isSimilarPassword check the password contains a word of the name or the local part of the email
+func isSimilarPassword(password string, personal []string) bool {
+	password = strings.ToLower(password)
+	for _, value := range personal {
+		value = strings.ToLower(value)
+		if at := strings.Index(value, "@"); at >= 0 {
+			value = value[:at]
+		}
+
+		words := strings.FieldsFunc(value, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		for _, word := range words {
+			if len(word) >= 3 && strings.Contains(password, word) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -9,7 +9,11 @@ import (
 )
 
 const (
-	CMDQueueSendMail = "cmd_queue_send_mail"
+	CMDQueueSendNotification = "cmd_queue_send_notification"
+
+	// Queues of mails and phone messages before notifications, their consumers still drain them
+	CMDQueueSendMail  = "cmd_queue_send_mail"
+	CMDQueueSendPhone = "cmd_queue_send_phone"
 )
 
 type QueueRabbitMQ struct {
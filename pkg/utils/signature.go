@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// SignatureCanonical func to build the string signed by a client:
+// method, path with query, timestamp and hex sha256 of body separated by new lines.
+func SignatureCanonical(method, path, timestamp string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		strings.ToUpper(method),
+		path,
+		timestamp,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// SignRequest func to create the hex HMAC-SHA256 signature of a canonical string.
+func SignRequest(secret, canonical string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(canonical))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifySignature func to compare a signature with the expected one in constant time.
+func VerifySignature(secret, canonical, signature string) bool {
+	expected := SignRequest(secret, canonical)
+
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
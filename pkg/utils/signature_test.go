@@ -0,0 +1,48 @@
+package utils
+
+import "testing"
+
+func TestSignatureCanonical(t *testing.T) {
+	got := SignatureCanonical("post", "/api/v1/auth/login?lang=en", "1700000000", []byte(`{"email":"user@sample.com"}`))
+	want := "POST\n/api/v1/auth/login?lang=en\n1700000000\n907e53f936466915e225e3779044acbc199b23407f6b1a441aefabba5cb2a9d7"
+	if got != want {
+		t.Errorf("canonical %q, want %q", got, want)
+	}
+
+	// Empty body is the hash of nothing
+	got = SignatureCanonical("GET", "/api/v1/role", "1700000000", nil)
+	want = "GET\n/api/v1/role\n1700000000\ne3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if got != want {
+		t.Errorf("canonical %q, want %q", got, want)
+	}
+}
+
+func TestSignRequest(t *testing.T) {
+	canonical := SignatureCanonical("POST", "/api/v1/auth/login?lang=en", "1700000000", []byte(`{"email":"user@sample.com"}`))
+	signature := "e2a3b50eddce266729ccbcc7f3f9f6fb485823435db2490a9e6d6cde972a3114"
+
+	if got := SignRequest("app-secret", canonical); got != signature {
+		t.Errorf("signature %s, want %s", got, signature)
+	}
+	if !VerifySignature("app-secret", canonical, signature) {
+		t.Error("signature is rejected")
+	}
+	if !VerifySignature("app-secret", canonical, "E2A3B50EDDCE266729CCBCC7F3F9F6FB485823435DB2490A9E6D6CDE972A3114") {
+		t.Error("upper case signature is rejected")
+	}
+	if VerifySignature("web-secret", canonical, signature) {
+		t.Error("signature of another secret is accepted")
+	}
+
+	// Any change of the request breaks the signature
+	for _, other := range []string{
+		SignatureCanonical("PUT", "/api/v1/auth/login?lang=en", "1700000000", []byte(`{"email":"user@sample.com"}`)),
+		SignatureCanonical("POST", "/api/v1/auth/login?lang=id", "1700000000", []byte(`{"email":"user@sample.com"}`)),
+		SignatureCanonical("POST", "/api/v1/auth/login?lang=en", "1700000001", []byte(`{"email":"user@sample.com"}`)),
+		SignatureCanonical("POST", "/api/v1/auth/login?lang=en", "1700000000", []byte(`{"email":"other@sample.com"}`)),
+	} {
+		if VerifySignature("app-secret", other, signature) {
+			t.Errorf("signature is accepted for %q", other)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package utils
+
+import (
+	"crypto/tls"
+	"errors"
+	"fiber-starter/config"
+	"fmt"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MailResult result of sending the mail to one recipient
+type MailResult struct {
+	To  string
+	Err error
+}
+
+// MailResults results of every recipient of a mail
+type MailResults []MailResult
+
+// Err reports the recipients the mail was not sent to, nil when sent to all of them
+func (r MailResults) Err() error {
+	var failed []string
+	for _, result := range r {
+		if result.Err != nil {
+			failed = append(failed, fmt.Sprintf("%s (%v)", result.To, result.Err))
+		}
+	}
+	if len(failed) <= 0 {
+		return nil
+	}
+
+	return fmt.Errorf("failed to send the email to %s", strings.Join(failed, ", "))
+}
+
+// failedMailResults the same error for every recipient
+func failedMailResults(to []string, err error) MailResults {
+	results := MailResults{}
+	for _, rcpt := range to {
+		results = append(results, MailResult{To: rcpt, Err: err})
+	}
+
+	return results
+}
+
+var (
+	mailConfig config.MailConfig
+	mailPool   *smtpPool
+
+	errMailNotConfigured = errors.New("mail is not configured")
+)
+
+// SetupMail func to set the transport of the mails, for smtp the server and the pool of its connections.
+func SetupMail(cfg config.MailConfig) error {
+	CloseMail()
+	mailConfig = cfg
+
+	switch cfg.Transport {
+	case MailTransportFile:
+		mailTransport = &fileMailTransport{dir: cfg.FileDir}
+		return nil
+	case MailTransportMemory:
+		mailTransport = mailMemory
+		return nil
+	case MailTransportSMTP:
+		mailTransport = &smtpMailTransport{}
+	default:
+		return fmt.Errorf(`mail transport %s is not supported`, cfg.Transport)
+	}
+
+	switch cfg.Encryption {
+	case config.MAIL_ENCRYPTION_SSL, config.MAIL_ENCRYPTION_STARTTLS, config.MAIL_ENCRYPTION_NONE:
+	default:
+		return fmt.Errorf(`mail encryption %s is not supported`, cfg.Encryption)
+	}
+	if cfg.PoolSize < 0 {
+		cfg.PoolSize = 0
+	}
+
+	mailPool = &smtpPool{cfg: cfg, idle: make(chan *smtpConn, cfg.PoolSize)}
+
+	return nil
+}
+
+// CloseMail func to quit the idle connections of the smtp pool.
+func CloseMail() {
+	if mailPool != nil {
+		mailPool.close()
+		mailPool = nil
+	}
+}
+
+// SendSMTP func to send the message built for every recipient in its own transaction, over a pooled connection.
+func SendSMTP(from string, to []string, build func(to string) ([]byte, error)) MailResults {
+	if mailPool == nil {
+		return failedMailResults(to, errMailNotConfigured)
+	}
+
+	results := MailResults{}
+
+	var c *smtpConn
+	for _, rcpt := range to {
+		msg, err := build(rcpt)
+		if err == nil {
+			c, err = sendPooled(c, from, rcpt, msg)
+		}
+		results = append(results, MailResult{To: rcpt, Err: err})
+	}
+	if c != nil {
+		mailPool.put(c)
+	}
+
+	return results
+}
+
+// sendPooled send the message over the connection or a pooled one, answers the connection still usable
+func sendPooled(c *smtpConn, from, rcpt string, msg []byte) (*smtpConn, error) {
+	// A connection which already sent a message may have been closed by the server meanwhile
+	reused := c != nil
+	for attempt := 0; ; attempt++ {
+		var err error
+		if c == nil {
+			c, reused, err = mailPool.get()
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		err = c.send(mailConfig.Timeout, from, rcpt, msg)
+		if err == nil {
+			return c, nil
+		}
+
+		// Rejected by the server, the connection is kept for the next recipients
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) {
+			if c.client.Reset() != nil {
+				c.close()
+				c = nil
+			}
+			return c, err
+		}
+
+		// Broken connection, a stale pooled one is retried once on a new connection
+		c.close()
+		c = nil
+		if !reused || attempt > 0 {
+			return nil, err
+		}
+	}
+}
+
+// smtpPool keeps idle connections to the smtp server to be reused
+type smtpPool struct {
+	cfg  config.MailConfig
+	idle chan *smtpConn
+}
+
+// get an idle connection or dial a new one, reports whether it is reused
+func (p *smtpPool) get() (*smtpConn, bool, error) {
+	for {
+		select {
+		case c := <-p.idle:
+			if time.Since(c.lastUsed) < p.cfg.IdleTimeout {
+				return c, true, nil
+			}
+			c.quit(p.cfg.Timeout)
+		default:
+			c, err := p.dial()
+			return c, false, err
+		}
+	}
+}
+
+func (p *smtpPool) put(c *smtpConn) {
+	c.lastUsed = time.Now()
+	select {
+	case p.idle <- c:
+	default:
+		c.quit(p.cfg.Timeout)
+	}
+}
+
+func (p *smtpPool) close() {
+	for {
+		select {
+		case c := <-p.idle:
+			c.quit(p.cfg.Timeout)
+		default:
+			return
+		}
+	}
+}
+
+func (p *smtpPool) dial() (*smtpConn, error) {
+	addr := net.JoinHostPort(p.cfg.Host, strconv.Itoa(p.cfg.Port))
+	dialer := &net.Dialer{Timeout: p.cfg.Timeout}
+	tlsConfig := &tls.Config{ServerName: p.cfg.Host}
+
+	var conn net.Conn
+	var err error
+	if p.cfg.Encryption == config.MAIL_ENCRYPTION_SSL {
+		conn, err = tls.DialWithDialer(dialer, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = dialer.Dial("tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	conn.SetDeadline(time.Now().Add(p.cfg.Timeout))
+
+	client, err := smtp.NewClient(conn, p.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c := &smtpConn{conn: conn, client: client}
+
+	if p.cfg.Encryption == config.MAIL_ENCRYPTION_STARTTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			c.close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err = client.StartTLS(tlsConfig); err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+
+	// Configured credentials are required, never send unauthenticated
+	if len(p.cfg.Username) > 0 {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			c.close()
+			return nil, errors.New("smtp server does not support AUTH")
+		}
+		if err = client.Auth(smtp.PlainAuth("", p.cfg.Username, p.cfg.Password, p.cfg.Host)); err != nil {
+			c.close()
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// smtpConn an authenticated connection to the smtp server
+type smtpConn struct {
+	conn     net.Conn
+	client   *smtp.Client
+	lastUsed time.Time
+}
+
+// send the message in one transaction, bounded by the timeout
+func (c *smtpConn) send(timeout time.Duration, from, to string, msg []byte) error {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+
+	if err := c.client.Mail(from); err != nil {
+		return err
+	}
+	if err := c.client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := c.client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(msg); err != nil {
+		return err
+	}
+
+	return w.Close()
+}
+
+func (c *smtpConn) quit(timeout time.Duration) {
+	c.conn.SetDeadline(time.Now().Add(timeout))
+	c.client.Quit()
+	c.close()
+}
+
+func (c *smtpConn) close() {
+	c.client.Close()
+}
@@ -0,0 +1,91 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// totpTestSecret the SHA1 seed of the RFC 6238 test vectors, "12345678901234567890"
+var totpTestSecret = totpEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeRFC4226(t *testing.T) {
+	expected := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, code := range expected {
+		if got := totpCode([]byte("12345678901234567890"), uint64(counter)); got != code {
+			t.Errorf("counter %d: got %s, want %s", counter, got, code)
+		}
+	}
+}
+
+func TestValidateTOTPRFC6238(t *testing.T) {
+	// Last six digits of the SHA1 vectors of RFC 6238 appendix B
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(totpTestSecret, tt.code, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("%d: code %s is rejected", tt.unix, tt.code)
+			continue
+		}
+		if step != tt.unix/TOTP_PERIOD {
+			t.Errorf("%d: step %d, want %d", tt.unix, step, tt.unix/TOTP_PERIOD)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	// Code of the previous and next step are accepted
+	for _, offset := range []int{-TOTP_SKEW, TOTP_SKEW} {
+		at := now.Add(time.Duration(offset*TOTP_PERIOD) * time.Second)
+		step, ok := ValidateTOTP(totpTestSecret, "050471", at)
+		if !ok || step != now.Unix()/TOTP_PERIOD {
+			t.Errorf("offset %d steps: step %d, ok %v", offset, step, ok)
+		}
+	}
+
+	// Beyond the skew it is rejected
+	for _, offset := range []int{-TOTP_SKEW - 1, TOTP_SKEW + 1} {
+		at := now.Add(time.Duration(offset*TOTP_PERIOD) * time.Second)
+		if _, ok := ValidateTOTP(totpTestSecret, "050471", at); ok {
+			t.Errorf("offset %d steps: code is accepted", offset)
+		}
+	}
+}
+
+func TestValidateTOTPInvalid(t *testing.T) {
+	now := time.Unix(59, 0)
+	if _, ok := ValidateTOTP(totpTestSecret, "000000", now); ok {
+		t.Error("wrong code is accepted")
+	}
+	if _, ok := ValidateTOTP("not base32!", "287082", now); ok {
+		t.Error("code of an invalid secret is accepted")
+	}
+	if _, ok := ValidateTOTP(" "+totpTestSecret+" ", " 287082 ", now); !ok {
+		t.Error("code is rejected for surrounding spaces")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(key) != totpSecretLength {
+		t.Errorf("secret of %d bytes, want %d", len(key), totpSecretLength)
+	}
+}